@@ -53,7 +53,7 @@ def format(input)
   input
 end
 
-Dir.glob('pkg/service/testdata/*.json').each do |file|
+Dir.glob('pkg/recalculation/testdata/golden/*/minwintid.json').each do |file|
   payload = JSON.parse(File.read(file))
 
   p file
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/navikt/vaktor-lonn/pkg/calculator"
+	"github.com/navikt/vaktor-lonn/pkg/recalculation"
+	"github.com/shopspring/decimal"
+)
+
+func runDiff(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("vaktor-calc diff", flag.ContinueOnError)
+	dir := flags.String("dir", "", "mappe med en undermappe per vaktperiode, med vaktplan.json, minwintid.json og eventuelt payroll.json")
+	rulesPath := flags.String("rules", "", "regelfil for beregningen som sammenlignes")
+	rulesVersion := flags.String("rules-version", "", "lås beregningen som sammenlignes til en versjon av reglene")
+	perMonth := flags.Bool("per-month", false, "del utbetalingen i en linje per måned")
+	baselineRulesPath := flags.String("baseline-rules", "", "regelfil for beregningen det sammenlignes mot")
+	baselineRulesVersion := flags.String("baseline-rules-version", "", "lås beregningen det sammenlignes mot til en versjon av reglene")
+	baselineArchived := flags.Bool("baseline-archived", false, "sammenlign mot utbetalingen i payroll.json i stedet for å beregne den")
+	format := flags.String("format", "table", "table eller json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		flags.Usage()
+		return errors.New("-dir is required")
+	}
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	cases, err := recalculation.LoadDir(*dir)
+	if err != nil {
+		return err
+	}
+
+	current := recalculation.Calculator{Options: calculator.Options{PerMonth: *perMonth}}
+	current.Rules, err = loadRules(*rulesPath, *rulesVersion)
+	if err != nil {
+		return err
+	}
+
+	baseline := recalculation.Calculator{Options: current.Options, Archived: *baselineArchived}
+	if !baseline.Archived {
+		baseline.Rules, err = loadRules(*baselineRulesPath, *baselineRulesVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	summary := recalculation.Diff(cases, baseline, current)
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(summary)
+	}
+
+	return printDiff(stdout, summary)
+}
+
+func printDiff(stdout io.Writer, summary recalculation.Summary) error {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+
+	if len(summary.Diffs) > 0 {
+		fmt.Fprintln(w, "Vaktperiode\tArtskode\tTimer før\tTimer etter\tEndring timer\tSum før\tSum etter\tEndring kr")
+		for _, diff := range summary.Diffs {
+			if diff.BaselineError != "" || diff.Error != "" {
+				fmt.Fprintf(w, "%v\tfeil\t\t\t\t%v\t%v\t\n", diff.Name, errorOrOK(diff.BaselineError), errorOrOK(diff.Error))
+				continue
+			}
+			for _, artskode := range diff.Artskoder {
+				fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", diff.Name, artskode.Artskode, artskode.BaselineHours, artskode.Hours,
+					signedHours(artskode.HoursDiff()), artskode.BaselineSum.StringFixed(2), artskode.Sum.StringFixed(2), signed(artskode))
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Vaktperioder:\t%v\n", summary.Plans)
+	fmt.Fprintf(w, "Endret:\t%v\n", summary.Changed)
+	fmt.Fprintf(w, "Feilet:\t%v\n", summary.Failed)
+
+	if len(summary.Artskoder) > 0 {
+		fmt.Fprintln(w, "\nArtskode\tTimer før\tTimer etter\tEndring timer\tSum før\tSum etter\tEndring kr")
+		for _, artskode := range summary.Artskoder {
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", artskode.Artskode, artskode.BaselineHours, artskode.Hours,
+				signedHours(artskode.HoursDiff()), artskode.BaselineSum.StringFixed(2), artskode.Sum.StringFixed(2), signed(artskode))
+		}
+	}
+
+	return w.Flush()
+}
+
+func signed(artskode recalculation.ArtskodeDiff) string {
+	diff := artskode.SumDiff()
+	if diff.IsNegative() {
+		return diff.StringFixed(2)
+	}
+	return "+" + diff.StringFixed(2)
+}
+
+// signedHours skriver endringen i timer med fortegn, slik %+d gjorde da timene var hele
+func signedHours(diff decimal.Decimal) string {
+	if diff.IsNegative() {
+		return diff.String()
+	}
+	return "+" + diff.String()
+}
+
+func errorOrOK(err string) string {
+	if err == "" {
+		return "ok"
+	}
+	return err
+}
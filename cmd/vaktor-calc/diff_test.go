@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/navikt/vaktor-lonn/pkg/recalculation"
+	"github.com/shopspring/decimal"
+)
+
+func TestRunDiff(t *testing.T) {
+	// Den arkiverte utbetalingen har 366,59 kr for lite i helgetillegg
+	archived := expectedPayroll(t)
+	archived.Artskoder.Helg.Sum = decimal.NewFromInt(3000)
+	body, err := json.Marshal(archived)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), filepath.Base(goldenCase))
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{recalculation.PlanFile, recalculation.MinWinTidFile} {
+		file, err := os.ReadFile(filepath.Join(goldenCase, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), file, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, recalculation.PayrollFile), body, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	args := []string{"diff", "-dir", filepath.Dir(dir), "-baseline-archived"}
+
+	t.Run("table", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if err := run(args, &stdout); err != nil {
+			t.Fatalf("run() returned an error: %v", err)
+		}
+
+		output := stdout.String()
+		if !hasRow(output, "vakt-pa-nyttarsaften", "2683", "24", "24", "+0", "3000.00", "3366.59", "+366.59") {
+			t.Errorf("run() output has no row for the changed artskode:\n%s", output)
+		}
+		if !hasRow(output, "Endret:", "1") {
+			t.Errorf("run() output does not count the plan as changed:\n%s", output)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var stdout bytes.Buffer
+		if err := run(append(args, "-format", "json"), &stdout); err != nil {
+			t.Fatalf("run() returned an error: %v", err)
+		}
+
+		var got recalculation.Summary
+		if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+			t.Fatalf("run() did not write a summary as JSON: %v", err)
+		}
+
+		artskoder := []recalculation.ArtskodeDiff{
+			{
+				Artskode:      "2683",
+				BaselineHours: decimal.NewFromInt(24),
+				Hours:         decimal.NewFromInt(24),
+				BaselineSum:   decimal.NewFromInt(3000),
+				Sum:           decimal.RequireFromString("3366.59"),
+			},
+		}
+		want := recalculation.Summary{
+			Plans:     1,
+			Changed:   1,
+			Artskoder: artskoder,
+			Diffs: []recalculation.PlanDiff{
+				{Name: "vakt-pa-nyttarsaften", ID: archived.ID.String(), Artskoder: artskoder},
+			},
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("run() mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+// vaktor-calc beregner utbetalingen for en vaktperiode lokalt, fra en vaktplan og en lagret respons fra MinWinTid.
+// Beregningen går gjennom samme kode som tjenesten, slik at omstridte beløp kan gjenskapes uten å gå via Vaktor.
+//
+//	go run ./cmd/vaktor-calc -plan vaktplan.json -minwintid minwintid.json
+//
+// Med diff beregnes en mappe med arkiverte vaktperioder på nytt, og forskjellene mot en annen beregning rapporteres.
+//
+//	go run ./cmd/vaktor-calc diff -dir arkiv -baseline-rules regler.json
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/navikt/vaktor-lonn/pkg/calculator"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/recalculation"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
+	"github.com/navikt/vaktor-lonn/pkg/service"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "vaktor-calc: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdout io.Writer) error {
+	if len(args) > 0 && args[0] == "diff" {
+		return runDiff(args[1:], stdout)
+	}
+
+	flags := flag.NewFlagSet("vaktor-calc", flag.ContinueOnError)
+	planPath := flags.String("plan", "", "vaktplanen fra Vaktor Plan, som JSON")
+	minWinTidPath := flags.String("minwintid", "", "lagret respons fra MinWinTid, eller en fil fra MWTmock")
+	rulesPath := flags.String("rules", "", "regelfil som brukes i stedet for den som er bygd inn")
+	rulesVersion := flags.String("rules-version", "", "lås beregningen til en versjon av reglene")
+	perMonth := flags.Bool("per-month", false, "del utbetalingen i en linje per måned")
+	format := flags.String("format", "table", "table eller json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *planPath == "" || *minWinTidPath == "" {
+		flags.Usage()
+		return errors.New("both -plan and -minwintid are required")
+	}
+	if *format != "table" && *format != "json" {
+		return fmt.Errorf("unknown format %q", *format)
+	}
+
+	plan, err := os.ReadFile(*planPath)
+	if err != nil {
+		return err
+	}
+
+	beredskapsvakt, err := recalculation.ParsePlan(plan)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Open(*minWinTidPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	response, err := service.DecodeMinWinTid(file)
+	if err != nil {
+		return err
+	}
+
+	ruleVersions, err := loadRules(*rulesPath, *rulesVersion)
+	if err != nil {
+		return err
+	}
+
+	payroll, message, err := service.CalculateSalary(beredskapsvakt, response, ruleVersions, calculator.Options{PerMonth: *perMonth})
+	if err != nil {
+		return fmt.Errorf("%v: %w", message, err)
+	}
+	if payroll == nil {
+		return errors.New(message)
+	}
+
+	if *format == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(payroll)
+	}
+
+	return printTable(stdout, *payroll)
+}
+
+// loadRules leser reglene fra en fil, eller de som er bygd inn, og låser dem til en versjon hvis den er oppgitt
+func loadRules(path, version string) (rules.Versions, error) {
+	ruleVersions, err := rules.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	if version != "" {
+		return ruleVersions.Pin(version)
+	}
+	return ruleVersions, nil
+}
+
+func printTable(stdout io.Writer, payroll models.Payroll) error {
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(w, "Vaktplan:\t%v\n", payroll.ID)
+	fmt.Fprintf(w, "Regler:\t%v\n", payroll.RulesVersion)
+	fmt.Fprintf(w, "Stillingskode:\t%v\n", payroll.Stillingskode)
+	fmt.Fprintf(w, "Godkjent av:\t%v (%v)\n", payroll.ApproverName, payroll.ApproverID)
+
+	fmt.Fprintln(w, "\nArtskode\tTimer\tSum")
+	for _, a := range payroll.Artskoder.Numbered() {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", a.Number, a.Hours, a.Sum.StringFixed(2))
+	}
+
+	if len(payroll.Residuals) > 0 {
+		fmt.Fprintln(w, "\nAvrunding\tArtskode\tMinutter")
+		for _, component := range slices.Sorted(maps.Keys(payroll.Residuals)) {
+			numbers := payroll.Residuals[component]
+			for _, number := range slices.Sorted(maps.Keys(numbers)) {
+				fmt.Fprintf(w, "%v\t%v\t%v\n", component, number, numbers[number])
+			}
+		}
+	}
+
+	if len(payroll.Lines) > 0 {
+		fmt.Fprintln(w, "\nStillingskode\tMåned\tArtskode\tTimer\tSum")
+		for _, line := range payroll.Lines {
+			for _, a := range line.Artskoder.Numbered() {
+				if a.Hours.IsZero() && a.Sum.IsZero() {
+					continue
+				}
+				fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", line.Stillingskode, line.Month, a.Number, a.Hours, a.Sum.StringFixed(2))
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\nDato\tLønn\tStillingskode\tÅrstimer\tDag\tNatt\tHelg\tUtvidet")
+	for _, day := range payroll.Days {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", day.Date, day.Salary, day.Stillingskode, day.AnnualHours,
+			day.Satser.Dag, day.Satser.Natt, day.Satser.Helg, day.Satser.Utvidet)
+	}
+
+	if len(payroll.Callouts) > 0 {
+		fmt.Fprintln(w, "\nUtrykning\tMinutter\tMinste utrykning\tUten tillegg\tArtskode\tSats\tMinutter")
+		for _, callout := range payroll.Callouts {
+			period := fmt.Sprintf("%v-%v", callout.Begin.Format("2006-01-02 15:04"), callout.End.Format("15:04"))
+			if len(callout.Parts) == 0 {
+				fmt.Fprintf(w, "%v\t%v\t%v\t%v\t\t\t\n", period, callout.Minutes, callout.MinimumMinutes, callout.UnpaidMinutes)
+			}
+			for i, part := range callout.Parts {
+				if i == 0 {
+					fmt.Fprintf(w, "%v\t%v\t%v\t%v\t", period, callout.Minutes, callout.MinimumMinutes, callout.UnpaidMinutes)
+				} else {
+					fmt.Fprint(w, "\t\t\t\t")
+				}
+				fmt.Fprintf(w, "%v\t%v\t%v\n", part.Artskode, part.Sats, part.Minutes)
+			}
+		}
+	}
+
+	if len(payroll.Warnings) > 0 {
+		fmt.Fprintln(w, "\nAdvarsler")
+		for _, warning := range payroll.Warnings {
+			fmt.Fprintln(w, warning)
+		}
+	}
+
+	return w.Flush()
+}
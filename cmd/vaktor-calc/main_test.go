@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/recalculation"
+)
+
+// goldenCase er en av vaktperiodene med forventet utbetaling fra pkg/recalculation
+const goldenCase = "../../pkg/recalculation/testdata/golden/vakt-pa-nyttarsaften"
+
+func expectedPayroll(t *testing.T) models.Payroll {
+	t.Helper()
+
+	body, err := os.ReadFile(filepath.Join(goldenCase, "expected_payroll.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var payroll models.Payroll
+	if err := json.Unmarshal(body, &payroll); err != nil {
+		t.Fatal(err)
+	}
+	return payroll
+}
+
+// hasRow sjekker om en av linjene fra tabwriter har de gitte kolonnene
+func hasRow(output string, columns ...string) bool {
+	for _, line := range strings.Split(output, "\n") {
+		if slices.Equal(strings.Fields(line), columns) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRunTable(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{
+		"-plan", filepath.Join(goldenCase, recalculation.PlanFile),
+		"-minwintid", filepath.Join(goldenCase, recalculation.MinWinTidFile),
+	}, &stdout)
+	if err != nil {
+		t.Fatalf("run() returned an error: %v", err)
+	}
+
+	want := expectedPayroll(t)
+	output := stdout.String()
+	if !hasRow(output, "Vaktplan:", want.ID.String()) {
+		t.Errorf("run() output has no row for the plan %v:\n%s", want.ID, output)
+	}
+	for _, a := range want.Artskoder.Numbered() {
+		if !hasRow(output, a.Number, a.Hours.String(), a.Sum.StringFixed(2)) {
+			t.Errorf("run() output has no row for artskode %v with %v hours and %v kr:\n%s", a.Number, a.Hours, a.Sum.StringFixed(2), output)
+		}
+	}
+}
+
+func TestRunJSON(t *testing.T) {
+	var stdout bytes.Buffer
+	err := run([]string{
+		"-plan", filepath.Join(goldenCase, recalculation.PlanFile),
+		"-minwintid", filepath.Join(goldenCase, recalculation.MinWinTidFile),
+		"-format", "json",
+	}, &stdout)
+	if err != nil {
+		t.Fatalf("run() returned an error: %v", err)
+	}
+
+	var got models.Payroll
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("run() did not write a payroll as JSON: %v", err)
+	}
+
+	if diff := cmp.Diff(expectedPayroll(t), got, cmpopts.IgnoreFields(models.Payroll{}, "CommitSHA")); diff != "" {
+		t.Errorf("run() mismatch (-want +got):\n%s", diff)
+	}
+}
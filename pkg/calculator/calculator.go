@@ -2,29 +2,86 @@ package calculator
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"slices"
+	"strings"
 	"time"
+	_ "time/tzdata"
 
 	"github.com/navikt/vaktor-lonn/pkg/callout"
-	"github.com/navikt/vaktor-lonn/pkg/ranges"
+	"github.com/navikt/vaktor-lonn/pkg/intervals"
+	"github.com/navikt/vaktor-lonn/pkg/skjema"
 
 	"github.com/navikt/vaktor-lonn/pkg/kronetillegg"
 	"github.com/navikt/vaktor-lonn/pkg/models"
 	"github.com/navikt/vaktor-lonn/pkg/overtime"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
 const (
 	VaktorDateFormat = "2006-01-02"
+	monthFormat      = "2006-01"
 )
 
+// Options sier hvordan utbetalingen skal deles opp
+type Options struct {
+	// PerMonth gir en egen linje i utbetalingen for hver kalendermåned, siden økonomi fører utbetalingene per måned
+	PerMonth bool
+}
+
+// oslo brukes for å gjøre om tidspunkter med tidssone til norsk veggklokke
+var oslo = func() *time.Location {
+	location, err := time.LoadLocation("Europe/Oslo")
+	if err != nil {
+		panic(err)
+	}
+	return location
+}()
+
+// toWallClock gjør om et tidspunkt til norsk veggklokke lagret som UTC, som resten av beregningen forventer.
+// Tidspunkter som allerede er i UTC regnes som veggklokke, slik Vaktor Plan alltid har sendt dem.
+func toWallClock(t time.Time) time.Time {
+	if t.Location() == time.UTC {
+		return t
+	}
+	local := t.In(oslo)
+	return time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), local.Nanosecond(), time.UTC)
+}
+
+// NormalizeSchedule tar imot vaktperioder av vilkårlig lengde, uavhengig av hvilken dato de er lagt under, og
+// deler dem opp per døgn. Perioder som overlapper eller møtes blir slått sammen, slik at ingen vakt telles to ganger.
+func NormalizeSchedule(schedule map[string][]models.Period) (map[string][]models.Period, error) {
+	var periods []intervals.Interval
+	for day, dayPeriods := range schedule {
+		for _, period := range dayPeriods {
+			interval := intervals.Interval{Begin: toWallClock(period.Begin), End: toWallClock(period.End)}
+			if interval.End.Before(interval.Begin) {
+				return nil, fmt.Errorf("period under %v ends before it begins: %v", day, interval)
+			}
+			periods = append(periods, interval)
+		}
+	}
+
+	normalized := make(map[string][]models.Period)
+	for _, interval := range intervals.New(periods...).SplitAtMidnight() {
+		day := interval.Begin.Format(VaktorDateFormat)
+		normalized[day] = append(normalized[day], models.Period{Begin: interval.Begin, End: interval.End})
+	}
+
+	return normalized, nil
+}
+
 // calculateMinutesToBePaid returns an object with the minutes you have been having guard duty each day in a given periode
-func calculateMinutesToBePaid(schedule map[string][]models.Period, timesheet map[string]models.TimeSheet) (map[string]models.GuardDuty, error) {
+func calculateMinutesToBePaid(schedule map[string][]models.Period, timesheet map[string]models.TimeSheet, r rules.Rules) (map[string]models.GuardDuty, error) {
 	guardHours := map[string]models.GuardDuty{}
 
 	for day, periods := range schedule {
-		currentDay := timesheet[day]
+		currentDay, ok := timesheet[day]
+		if !ok {
+			return nil, fmt.Errorf("there is no timesheet for %v", day)
+		}
 		date := currentDay.Date
 		dutyHours := models.GuardDuty{}
 
@@ -33,82 +90,36 @@ func calculateMinutesToBePaid(schedule map[string][]models.Period, timesheet map
 		dutyHours.Helgetillegg += modifier
 
 		for _, period := range periods {
-			// sjekk om man har vakt i perioden 00-06
-			minutesWithGuardDuty := calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-				Begin: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
-				End:   time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
-			}, currentDay.Clockings)
-			dutyHours.Hvilende0006 += minutesWithGuardDuty
-
-			// sjekk om man har vakt i perioden 20-24
-			minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-				Begin: time.Date(date.Year(), date.Month(), date.Day(), 20, 0, 0, 0, time.UTC),
-				End:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour),
-			}, currentDay.Clockings)
-			dutyHours.Hvilende2000 += minutesWithGuardDuty
-
-			// sjekk om man har vakt i perioden 06-20
-			minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-				Begin: time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
-				End:   time.Date(date.Year(), date.Month(), date.Day(), 20, 0, 0, 0, time.UTC),
-			}, currentDay.Clockings)
-			dutyHours.Hvilende0620 += minutesWithGuardDuty
+			// sjekk hvor mye vakt man har i hvert av tidsrommene i særavtalen
+			for _, window := range r.GuardDutyWindows {
+				if !window.Days.Includes(date) {
+					continue
+				}
+
+				bucket, _ := dutyHours.Bucket(window.Bucket)
+				*bucket += calculateMinutesWithGuardDutyInPeriod(period, window.Period(date), currentDay.Clockings)
+			}
 
 			// TODO: Disse modifiers burde begge trekkes fra, tungvindt å legge til et negativt tall
-			kjernetidModifier := calculateGuardDutyInKjernetid(currentDay, period)
+			kjernetidModifier := calculateGuardDutyInKjernetid(currentDay, period, r)
 			dutyHours.Hvilende0620 -= kjernetidModifier
 
 			// TODO: Lag en skikkelig test av denne
-			maxGuardDutyModifier := calculateMaxGuardDutyTime(currentDay, dutyHours.Hvilende0620+dutyHours.Hvilende2000+dutyHours.Hvilende0006)
+			maxGuardDutyModifier := calculateMaxGuardDutyTime(currentDay, dutyHours.Hvilende0620+dutyHours.Hvilende2000+dutyHours.Hvilende0006, r)
 			dutyHours.Hvilende0620 += maxGuardDutyModifier
 
-			if isWeekend(currentDay.Date) {
-				// sjekk om man har vakt i perioden 00-24
-				minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-					Begin: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
-					End:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour),
-				}, currentDay.Clockings)
-				dutyHours.Helgetillegg += minutesWithGuardDuty
-			} else {
-				// sjekk om man har vakt i perioden 06-07
-				minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-					Begin: time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
-					End:   time.Date(date.Year(), date.Month(), date.Day(), 7, 0, 0, 0, time.UTC),
-				}, currentDay.Clockings)
-				dutyHours.Skifttillegg += minutesWithGuardDuty
-
-				// sjekk om man har vakt i perioden 17-20
-				minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-					Begin: time.Date(date.Year(), date.Month(), date.Day(), 17, 0, 0, 0, time.UTC),
-					End:   time.Date(date.Year(), date.Month(), date.Day(), 20, 0, 0, 0, time.UTC),
-				}, currentDay.Clockings)
-				dutyHours.Skifttillegg += minutesWithGuardDuty
-			}
-
 			dutyHours.IsWeekend = isWeekend(currentDay.Date)
 
 			// Det er ingen økonomiske fordeler med helligdager i helg, kun i ukedagene.
 			// Derfor bryr vi oss ikke om helligdager i helgene.
-			if !dutyHours.IsWeekend && isHoliday(currentDay.FormName) {
-				if currentDay.FormName == "Helligdag" {
+			if holiday, ok := r.Holiday(currentDay.FormName); !dutyHours.IsWeekend && ok {
+				if holiday.Kjernetid == nil {
 					dutyHours.Helligdag0620 = dutyHours.Hvilende0620
 					dutyHours.Hvilende0620 = 0
 				} else {
-					// Tre dager i året er det kun helligdag etter kl12, så de må spesialhåndteres
-					// det er kun tiden før kjernetid som er relevant for helligdager som starter kl12.
-					if currentDay.FormName == "Nyttårsaften 1000-1200 *" {
-						// Nyttårsaften har kjernetid fra kl10 til kl12
-						minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-							Begin: time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
-							End:   time.Date(date.Year(), date.Month(), date.Day(), 10, 0, 0, 0, time.UTC),
-						}, currentDay.Clockings)
-					} else {
-						// Julaften og onsdag før påske har kjernetid fra kl08 til kl12
-						minutesWithGuardDuty = calculateMinutesWithGuardDutyInPeriod(period, models.Period{
-							Begin: time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
-							End:   time.Date(date.Year(), date.Month(), date.Day(), 8, 0, 0, 0, time.UTC),
-						}, currentDay.Clockings)
-					}
+					// Noen dager i året er det kun helligdag etter kjernetiden starter, så de må spesialhåndteres.
+					// Det er kun tiden før kjernetid som er relevant for helligdager som starter midt på dagen.
+					minutesWithGuardDuty := calculateMinutesBeforeHoliday(currentDay, period, *holiday.Kjernetid, r)
 					dutyHours.Helligdag0620 = dutyHours.Hvilende0620 - minutesWithGuardDuty
 					dutyHours.Hvilende0620 = minutesWithGuardDuty
 				}
@@ -120,13 +131,35 @@ func calculateMinutesToBePaid(schedule map[string][]models.Period, timesheet map
 	return guardHours, nil
 }
 
+// calculateMinutesBeforeHoliday returnerer minuttene med hvilende vakt på dagtid før en helligdag starter midt på dagen
+func calculateMinutesBeforeHoliday(currentDay models.TimeSheet, period models.Period, kjernetid rules.Window, r rules.Rules) int64 {
+	holidayBegins := kjernetid.Period(currentDay.Date).Begin
+
+	var minutes int64
+	for _, window := range r.GuardDutyWindows {
+		if window.Bucket != "hvilende0620" || !window.Days.Includes(currentDay.Date) {
+			continue
+		}
+
+		beforeHoliday := window.Period(currentDay.Date)
+		if beforeHoliday.End.After(holidayBegins) {
+			beforeHoliday.End = holidayBegins
+		}
+		if beforeHoliday.End.After(beforeHoliday.Begin) {
+			minutes += calculateMinutesWithGuardDutyInPeriod(period, beforeHoliday, currentDay.Clockings)
+		}
+	}
+
+	return minutes
+}
+
 // calculateMaxGuardDutyTime fjerner minutter som overstiger lovlig antall tid med vakt man kan gå per dag.
-func calculateMaxGuardDutyTime(currentDay models.TimeSheet, totalGuardDutyInADayInMinutes float64) float64 {
-	if isWeekend(currentDay.Date) || currentDay.FormName == "Helligdag" {
+func calculateMaxGuardDutyTime(currentDay models.TimeSheet, totalGuardDutyInADayInMinutes int64, r rules.Rules) int64 {
+	if isWeekend(currentDay.Date) || r.IsFullDayHoliday(currentDay.FormName) {
 		return 0
 	}
 
-	maxGuardDutyInMinutes := 24*60 - currentDay.WorkingHours*60
+	maxGuardDutyInMinutes := 24*60 - currentDay.WorkingMinutes()
 	if totalGuardDutyInADayInMinutes > maxGuardDutyInMinutes {
 		return maxGuardDutyInMinutes - totalGuardDutyInADayInMinutes
 	}
@@ -138,32 +171,36 @@ func isWeekend(day time.Time) bool {
 	return day.Weekday() == time.Saturday || day.Weekday() == time.Sunday
 }
 
-func isHoliday(formName string) bool {
-	holidays := []string{"Helligdag", "Julaften 0800-1200 *", "Onsdag før Påske 0800-1200 *", "Nyttårsaften 1000-1200 *"}
-	return slices.Contains(holidays, formName)
-}
-
 // calculateGuardDutyInKjernetid sjekker om man hadde vakt i kjernetiden. Man vil ikke kunne få vakttillegg i
 // kjernetiden, da andre skal være på jobb til å ta seg av uforutsette hendelser.
-func calculateGuardDutyInKjernetid(currentDay models.TimeSheet, period models.Period) float64 {
-	if isWeekend(currentDay.Date) || currentDay.FormName == "Helligdag" {
+func calculateGuardDutyInKjernetid(currentDay models.TimeSheet, period models.Period, r rules.Rules) int64 {
+	if isWeekend(currentDay.Date) || r.IsFullDayHoliday(currentDay.FormName) {
 		return 0
 	}
 
-	kjernetid := createKjernetid(currentDay.Date, currentDay.FormName)
+	kjernetid := createKjernetid(currentDay.Date, currentDay.FormName, r)
 	return calculateMinutesWithGuardDutyInPeriod(period, kjernetid, currentDay.Clockings)
 }
 
-// createKjernetid returns the current day kjernetid. Except for three days, it's always from 0900 till 1430
-func createKjernetid(date time.Time, formName string) models.Period {
-	startOfKjernetid := time.Date(date.Year(), date.Month(), date.Day(), 9, 0, 0, 0, time.UTC)
-	endOfKjernetid := time.Date(date.Year(), date.Month(), date.Day(), 14, 30, 0, 0, time.UTC)
-	if formName == "Julaften 0800-1200 *" || formName == "Onsdag før Påske 0800-1200 *" {
-		startOfKjernetid = time.Date(date.Year(), date.Month(), date.Day(), 8, 0, 0, 0, time.UTC)
-		endOfKjernetid = time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC)
-	} else if formName == "Nyttårsaften 1000-1200 *" {
-		startOfKjernetid = time.Date(date.Year(), date.Month(), date.Day(), 10, 0, 0, 0, time.UTC)
-		endOfKjernetid = time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC)
+// createKjernetid returns the current day kjernetid, as given by the rules. Some holidays have their own kjernetid.
+// Skjemaer med et eget tidsrom, typisk for deltid, får kjernetiden avgrenset til sitt tidsrom.
+func createKjernetid(date time.Time, formName string, r rules.Rules) models.Period {
+	kjernetid := r.KjernetidFor(formName).Period(date)
+	startOfKjernetid := kjernetid.Begin
+	endOfKjernetid := kjernetid.End
+
+	daySkjema := skjema.Parse(formName, 0)
+	if daySkjema.HasFlexBand() {
+		startOfDay := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+		if flexBegin := startOfDay.Add(daySkjema.FlexBegin); flexBegin.After(startOfKjernetid) {
+			startOfKjernetid = flexBegin
+		}
+		if flexEnd := startOfDay.Add(daySkjema.FlexEnd); flexEnd.Before(endOfKjernetid) {
+			endOfKjernetid = flexEnd
+		}
+		if endOfKjernetid.Before(startOfKjernetid) {
+			endOfKjernetid = startOfKjernetid
+		}
 	}
 
 	return models.Period{
@@ -173,13 +210,13 @@ func createKjernetid(date time.Time, formName string) models.Period {
 }
 
 // calculateDaylightSavingTimeModifier returns either -60 or 60 minutes if $day is when the clock is advanced
-func calculateDaylightSavingTimeModifier(periods []models.Period, date time.Time) float64 {
+func calculateDaylightSavingTimeModifier(periods []models.Period, date time.Time) int64 {
 	nightShift := models.Period{
 		Begin: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
 		End:   time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
 	}
 
-	var minutes float64
+	var minutes int64
 	for _, period := range periods {
 		minutes += calculateMinutesWithGuardDutyInPeriod(period, nightShift, []models.Clocking{})
 	}
@@ -203,96 +240,309 @@ func calculateDaylightSavingTimeModifier(periods []models.Period, date time.Time
 }
 
 // calculateMinutesWithGuardDutyInPeriod return the number of minutes that you have non-working guard duty
-func calculateMinutesWithGuardDutyInPeriod(vaktPeriod models.Period, compPeriod models.Period, timesheet []models.Clocking) float64 {
-	dutyRange := ranges.CreateForPeriod(vaktPeriod, compPeriod)
-	minutesWithGuardDuty := 0.0
-
-	if dutyRange != nil {
-		for _, workHours := range timesheet {
-			if workHours.OtG {
-				// Overtid ved utrykning regnes ikke som arbeidstid
-				continue
-			}
+func calculateMinutesWithGuardDutyInPeriod(vaktPeriod models.Period, compPeriod models.Period, timesheet []models.Clocking) int64 {
+	guardDuty := intervals.Between(vaktPeriod.Begin, vaktPeriod.End).
+		Intersect(intervals.Between(compPeriod.Begin, compPeriod.End))
+
+	return guardDuty.Subtract(workingHours(timesheet)).Minutes()
+}
 
-			workRange := ranges.FromTime(workHours.In, workHours.Out)
-			minutesWithGuardDuty += ranges.CalculateMinutesOverlapping(workRange, *dutyRange)
+// workingHours returns the time worked, rounded down to whole minutes like MinWinTid does
+func workingHours(timesheet []models.Clocking) intervals.Set {
+	var worked []intervals.Interval
+	for _, workHours := range timesheet {
+		if workHours.OtG {
+			// Overtid ved utrykning regnes ikke som arbeidstid
+			continue
 		}
 
-		return dutyRange.Count() - minutesWithGuardDuty
+		worked = append(worked, intervals.Interval{
+			Begin: workHours.In.Truncate(time.Minute),
+			End:   workHours.Out.Truncate(time.Minute),
+		})
 	}
 
-	return minutesWithGuardDuty
+	return intervals.New(worked...)
 }
 
-func getDailySalaries(timesheet map[string]models.TimeSheet) map[string][]string {
-	salaries := make(map[string][]string)
-	for date, period := range timesheet {
-		key := period.Salary.String()
-		if _, ok := salaries[key]; !ok {
-			salaries[key] = []string{date}
-		} else {
-			salaries[key] = append(salaries[key], date)
+// dayPricing er det en dag med vakt blir priset etter
+type dayPricing struct {
+	salary        decimal.Decimal
+	stillingskode string
+	annualHours   int64
+	satser        models.Satser
+	rules         rules.Rules
+}
+
+// sameRules sier om to dager beregnes etter samme versjon av reglene
+func sameRules(a, b dayPricing) bool {
+	return a.rules.Version == b.rules.Version
+}
+
+// sameRates sier om to dager gir like kronetillegg og utrykningstillegg
+func sameRates(a, b dayPricing) bool {
+	return sameRules(a, b) && a.satser.Equal(b.satser)
+}
+
+// sameHourlySalary sier om to dager gir lik timelønn for overtid
+func sameHourlySalary(a, b dayPricing) bool {
+	return sameRules(a, b) && a.salary.Equal(b.salary) && a.annualHours == b.annualHours
+}
+
+// getDayPricing finner lønnen, stillingskoden, årstimene, satsene og versjonen av reglene for hver dag i vaktplanen
+func getDayPricing(schedule map[string][]models.Period, minWinTid models.MinWinTid, versions rules.Versions) (map[string]dayPricing, error) {
+	pricing := make(map[string]dayPricing)
+	for date := range schedule {
+		parsed, err := time.Parse(VaktorDateFormat, date)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := versions.At(parsed)
+		if err != nil {
+			return nil, err
+		}
+
+		// En dag uten timeliste ville blitt priset med lønn 0 og uten stillingskode
+		day, ok := minWinTid.Timesheet[date]
+		if !ok {
+			return nil, fmt.Errorf("there is no timesheet for %v", date)
+		}
+		pricing[date] = dayPricing{
+			salary:        day.Salary,
+			stillingskode: day.Stillingskode,
+			annualHours:   r.Overtime.AnnualHoursFor(day),
+			satser:        r.Satser,
+			rules:         r,
 		}
 	}
 
-	return salaries
+	return pricing, nil
 }
 
-func getStillingskode(timesheet map[string]models.TimeSheet) (string, error) {
-	var stillingskode string
-	for _, period := range timesheet {
-		if stillingskode == "" {
-			stillingskode = period.Stillingskode
-			continue
+// pricingGroup er dagene som blir priset likt
+type pricingGroup struct {
+	pricing dayPricing
+	dates   []string
+}
+
+// groupDays grupperer dagene etter prisen, der same sier hvilke deler av prisen som må være like
+func groupDays(pricing map[string]dayPricing, same func(a, b dayPricing) bool) []pricingGroup {
+	dates := make([]string, 0, len(pricing))
+	for date := range pricing {
+		dates = append(dates, date)
+	}
+	slices.Sort(dates)
+
+	var groups []pricingGroup
+	for _, date := range dates {
+		i := slices.IndexFunc(groups, func(group pricingGroup) bool {
+			return same(group.pricing, pricing[date])
+		})
+		if i == -1 {
+			groups = append(groups, pricingGroup{pricing: pricing[date]})
+			i = len(groups) - 1
 		}
-		if stillingskode != period.Stillingskode {
-			return "", fmt.Errorf("stillingskode has changed")
+		groups[i].dates = append(groups[i].dates, date)
+	}
+
+	return groups
+}
+
+// payrollLine er dagene som skal føres på samme linje i utbetalingen
+type payrollLine struct {
+	stillingskode string
+	month         string
+}
+
+// getPayrollLines grupperer datoene i timelisten etter stillingskode, og etter måned når utbetalingen deles per måned
+func getPayrollLines(timesheet map[string]models.TimeSheet, options Options) map[payrollLine][]string {
+	lines := make(map[payrollLine][]string)
+	for date, period := range timesheet {
+		key := payrollLine{stillingskode: period.Stillingskode}
+		if options.PerMonth {
+			key.month = period.Date.Format(monthFormat)
 		}
+		lines[key] = append(lines[key], date)
 	}
 
-	return stillingskode, nil
+	return lines
 }
 
-func GuarddutySalary(plan models.Vaktplan, minWinTid models.MinWinTid) (models.Payroll, error) {
-	minutes, err := calculateMinutesToBePaid(plan.Schedule, minWinTid.Timesheet)
+func calculateArtskoder(schedule map[string][]models.Period, minWinTid models.MinWinTid, versions rules.Versions, payroll *models.Payroll) error {
+	// Hver dag prises etter lønnen, stillingskoden, satsene og reglene for dagen. Tilleggene regnes ut for hver gruppe
+	// av dager som prises likt for tillegget, slik at en endring midt i perioden gjelder fra dagen endringen skjer.
+	pricing, err := getDayPricing(schedule, minWinTid, versions)
 	if err != nil {
-		return models.Payroll{}, err
+		return err
+	}
+	for date, day := range pricing {
+		payroll.Days = append(payroll.Days, models.PricedDay{
+			Date:          date,
+			Salary:        day.salary,
+			Stillingskode: day.stillingskode,
+			AnnualHours:   day.annualHours,
+			Satser:        day.satser,
+			RulesVersion:  day.rules.Version,
+		})
+	}
+
+	minutes := make(map[string]models.GuardDuty)
+	for _, group := range groupDays(pricing, sameRules) {
+		groupSchedule := make(map[string][]models.Period)
+		for _, date := range group.dates {
+			groupSchedule[date] = schedule[date]
+		}
+
+		groupMinutes, err := calculateMinutesToBePaid(groupSchedule, minWinTid.Timesheet, group.pricing.rules)
+		if err != nil {
+			return err
+		}
+		maps.Copy(minutes, groupMinutes)
+	}
+
+	for _, group := range groupDays(pricing, sameRates) {
+		groupSchedule := make(map[string][]models.Period)
+		groupTimesheet := make(map[string]models.TimeSheet)
+		groupMinutes := make(map[string]models.GuardDuty)
+		for _, date := range group.dates {
+			groupSchedule[date] = schedule[date]
+			groupMinutes[date] = minutes[date]
+			if day, ok := minWinTid.Timesheet[date]; ok {
+				groupTimesheet[date] = day
+			}
+		}
+
+		r := group.pricing.rules
+		kronetillegg.Calculate(groupMinutes, group.pricing.satser, r.Kronetillegg, r.Rounding, payroll)
+		callout.Calculate(groupSchedule, groupTimesheet, group.pricing.satser, r, payroll)
+	}
+
+	for _, group := range groupDays(pricing, sameHourlySalary) {
+		groupMinutes := make(map[string]models.GuardDuty)
+		for _, date := range group.dates {
+			groupMinutes[date] = minutes[date]
+		}
+
+		r := group.pricing.rules
+		overtime.Calculate(groupMinutes, group.pricing.salary, group.pricing.annualHours, r.Overtime, r.Rounding, payroll)
 	}
 
-	stillingskode, err := getStillingskode(minWinTid.Timesheet)
+	slices.SortFunc(payroll.Days, func(a, b models.PricedDay) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	slices.SortFunc(payroll.Callouts, func(a, b models.Callout) int {
+		return a.Begin.Compare(b.Begin)
+	})
+
+	return nil
+}
+
+// GuarddutySalary beregner utbetalingen for en vaktplan. Har stillingskoden endret seg i løpet av perioden
+// blir utbetalingen beregnet for hver stillingskode for seg, og lagt til som egne linjer i utbetalingen.
+// Stillingskoden på selve utbetalingen er da stillingskoden man hadde ved slutten av perioden.
+// Med Options.PerMonth blir utbetalingen også delt per kalendermåned, etter hvilken dag minuttene falt på.
+// Hver dag beregnes etter versjonen av reglene som gjaldt den dagen, og versjonene blir rapportert sammen med
+// utbetalingen.
+func GuarddutySalary(plan models.Vaktplan, minWinTid models.MinWinTid, versions rules.Versions, options Options) (models.Payroll, error) {
+	schedule, err := NormalizeSchedule(plan.Schedule)
 	if err != nil {
 		return models.Payroll{}, err
 	}
+	plan.Schedule = schedule
 
 	payroll := &models.Payroll{
-		ID:            plan.ID,
-		ApproverID:    minWinTid.ApproverID,
-		ApproverName:  minWinTid.ApproverName,
-		CommitSHA:     os.Getenv("NAIS_APP_IMAGE"),
-		Stillingskode: stillingskode,
+		ID:           plan.ID,
+		ApproverID:   minWinTid.ApproverID,
+		ApproverName: minWinTid.ApproverName,
+		CommitSHA:    os.Getenv("NAIS_APP_IMAGE"),
 	}
 
-	kronetillegg.Calculate(minutes, minWinTid.Satser, payroll)
-	callout.Calculate(plan.Schedule, minWinTid.Timesheet, minWinTid.Satser, payroll)
+	lines := getPayrollLines(minWinTid.Timesheet, options)
+	if len(lines) <= 1 {
+		for line := range lines {
+			payroll.Stillingskode = line.stillingskode
+		}
 
-	salariesWithDates := getDailySalaries(minWinTid.Timesheet)
-	if err != nil {
-		return models.Payroll{}, err
+		if err := calculateArtskoder(plan.Schedule, minWinTid, versions, payroll); err != nil {
+			return models.Payroll{}, err
+		}
+
+		payroll.RulesVersion = rulesVersion(payroll.Days)
+		return *payroll, nil
 	}
 
-	for salaryAsString, dates := range salariesWithDates {
-		salaryBasedMinutes := make(map[string]models.GuardDuty)
+	for day := range plan.Schedule {
+		if _, ok := minWinTid.Timesheet[day]; !ok {
+			return models.Payroll{}, fmt.Errorf("payroll is split in lines, and there is no timesheet for %v", day)
+		}
+	}
+
+	var lastDate string
+	for key, dates := range lines {
+		schedule := make(map[string][]models.Period)
+		timesheet := make(map[string]models.TimeSheet)
 		for _, date := range dates {
-			salaryBasedMinutes[date] = minutes[date]
+			timesheet[date] = minWinTid.Timesheet[date]
+			if periods, ok := plan.Schedule[date]; ok {
+				schedule[date] = periods
+			}
+
+			if date > lastDate {
+				lastDate = date
+				payroll.Stillingskode = key.stillingskode
+			}
 		}
 
-		salary, err := decimal.NewFromString(salaryAsString)
-		if err != nil {
+		if len(schedule) == 0 {
+			continue
+		}
+
+		partial := minWinTid
+		partial.Timesheet = timesheet
+
+		line := &models.Payroll{}
+		if err := calculateArtskoder(schedule, partial, versions, line); err != nil {
 			return models.Payroll{}, err
 		}
 
-		overtime.Calculate(salaryBasedMinutes, salary, payroll)
+		payroll.Lines = append(payroll.Lines, models.PayrollLine{
+			Stillingskode: key.stillingskode,
+			Month:         key.month,
+			Artskoder:     line.Artskoder,
+			Residuals:     line.Residuals,
+		})
+		payroll.Artskoder = payroll.Artskoder.Add(line.Artskoder)
+		payroll.Residuals.Merge(line.Residuals)
+		payroll.Callouts = append(payroll.Callouts, line.Callouts...)
+		payroll.Days = append(payroll.Days, line.Days...)
 	}
 
+	slices.SortFunc(payroll.Lines, func(a, b models.PayrollLine) int {
+		if c := strings.Compare(a.Month, b.Month); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Stillingskode, b.Stillingskode)
+	})
+	slices.SortFunc(payroll.Callouts, func(a, b models.Callout) int {
+		return a.Begin.Compare(b.Begin)
+	})
+	slices.SortFunc(payroll.Days, func(a, b models.PricedDay) int {
+		return strings.Compare(a.Date, b.Date)
+	})
+	payroll.RulesVersion = rulesVersion(payroll.Days)
+
 	return *payroll, nil
 }
+
+// rulesVersion returnerer versjonene av reglene dagene er beregnet etter, i rekkefølgen de ble brukt
+func rulesVersion(days []models.PricedDay) string {
+	var versions []string
+	for _, day := range days {
+		if !slices.Contains(versions, day.RulesVersion) {
+			versions = append(versions, day.RulesVersion)
+		}
+	}
+
+	return strings.Join(versions, ", ")
+}
@@ -1,6 +1,7 @@
 package calculator
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -9,9 +10,16 @@ import (
 	"github.com/navikt/vaktor-lonn/pkg/kronetillegg"
 	"github.com/navikt/vaktor-lonn/pkg/models"
 	"github.com/navikt/vaktor-lonn/pkg/overtime"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
+// testRules er reglene fra særavtalen som gjaldt da testene ble skrevet
+var testRules, _ = rules.Default().At(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
+// testVersions gir testRules for alle datoene i testene
+var testVersions = rules.Versions{testRules}
+
 func Test_calculateMinutesWithGuardDutyInPeriod(t *testing.T) {
 	type args struct {
 		dutyPeriod models.Period
@@ -22,7 +30,7 @@ func Test_calculateMinutesWithGuardDutyInPeriod(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want float64
+		want int64
 	}{
 		{
 			name: "Vanlig arbeidsdag",
@@ -193,7 +201,7 @@ func Test_calculateDaylightSavingTimeModifier(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want float64
+		want int64
 	}{
 		{
 			name: "Stiller klokken tilbake (normaltid)",
@@ -290,10 +298,32 @@ func Test_createKjernetid(t *testing.T) {
 				End:   time.Date(2021, 12, 24, 12, 0, 0, 0, time.UTC),
 			},
 		},
+		{
+			name: "Kjernetid for deltid",
+			args: args{
+				date:     time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC),
+				formName: "Deltid 50% 0800-1145",
+			},
+			want: models.Period{
+				Begin: time.Date(2022, 11, 7, 9, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 11, 7, 11, 45, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Ingen kjernetid for skjema utenfor kjernetiden",
+			args: args{
+				date:     time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC),
+				formName: "Kveld 1500-2230",
+			},
+			want: models.Period{
+				Begin: time.Date(2022, 11, 7, 15, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 11, 7, 15, 0, 0, 0, time.UTC),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := createKjernetid(tt.args.date, tt.args.formName)
+			got := createKjernetid(tt.args.date, tt.args.formName, testRules)
 			if diff := cmp.Diff(tt.want, got); diff != "" {
 				t.Errorf("createKjernetid() mismatch (-want +got):\n%s", diff)
 			}
@@ -309,7 +339,7 @@ func Test_calculateGuardDutyInKjernetid(t *testing.T) {
 	tests := []struct {
 		name string
 		args args
-		want float64
+		want int64
 	}{
 		{
 			name: "Vanlig arbeid i kjernetid",
@@ -420,13 +450,60 @@ func Test_calculateGuardDutyInKjernetid(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := calculateGuardDutyInKjernetid(tt.args.currentDay, tt.args.period); got != tt.want {
+			if got := calculateGuardDutyInKjernetid(tt.args.currentDay, tt.args.period, testRules); got != tt.want {
 				t.Errorf("calculateGuardDutyInKjernetid() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func Test_calculateMaxGuardDutyTime(t *testing.T) {
+	tests := []struct {
+		name         string
+		workingHours float64
+		date         time.Time
+		total        int64
+		want         int64
+	}{
+		{
+			name:         "innenfor maks vakt",
+			workingHours: 7.75,
+			date:         time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC),
+			total:        24*60 - 465,
+			want:         0,
+		},
+		{
+			name:         "mer vakt enn døgnet minus arbeidstid",
+			workingHours: 7.75,
+			date:         time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC),
+			total:        24 * 60,
+			want:         -465,
+		},
+		{
+			name:         "arbeidstid som ikke er et helt antall minutter i flyttall",
+			workingHours: 7.45,
+			date:         time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC),
+			total:        24*60 - 446,
+			want:         -1,
+		},
+		{
+			name:         "ingen maks i helgen",
+			workingHours: 7.75,
+			date:         time.Date(2022, 11, 5, 0, 0, 0, 0, time.UTC),
+			total:        24 * 60,
+			want:         0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			currentDay := models.TimeSheet{Date: tt.date, WorkingHours: tt.workingHours}
+			if got := calculateMaxGuardDutyTime(currentDay, tt.total, testRules); got != tt.want {
+				t.Errorf("calculateMaxGuardDutyTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_calculateMinutesToBePaid(t *testing.T) {
 	type args struct {
 		schedule  map[string][]models.Period
@@ -631,7 +708,7 @@ func Test_calculateMinutesToBePaid(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := calculateMinutesToBePaid(tt.args.schedule, tt.args.timesheet)
+			got, err := calculateMinutesToBePaid(tt.args.schedule, tt.args.timesheet, testRules)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("calculateMinutesToBePaid() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -808,23 +885,23 @@ func TestHoursGuarddutySalary(t *testing.T) {
 			want: models.Artskoder{
 				Morgen: models.Artskode{
 					Sum:   decimal.NewFromFloat(5614.86),
-					Hours: 30,
+					Hours: decimal.NewFromInt(30),
 				},
 				Kveld: models.Artskode{
 					Sum:   decimal.NewFromFloat(3743.24),
-					Hours: 20,
+					Hours: decimal.NewFromInt(20),
 				},
 				Dag: models.Artskode{
 					Sum:   decimal.NewFromFloat(4235.27),
-					Hours: 31,
+					Hours: decimal.NewFromInt(31),
 				},
 				Helg: models.Artskode{
 					Sum:   decimal.NewFromFloat(9327.78),
-					Hours: 48,
+					Hours: decimal.NewFromInt(48),
 				},
 				Skift: models.Artskode{
 					Sum:   decimal.NewFromFloat(100),
-					Hours: 20,
+					Hours: decimal.NewFromInt(20),
 				},
 			},
 		},
@@ -982,23 +1059,23 @@ func TestHoursGuarddutySalary(t *testing.T) {
 			want: models.Artskoder{
 				Morgen: models.Artskode{
 					Sum:   decimal.NewFromFloat(5614.86),
-					Hours: 30,
+					Hours: decimal.NewFromInt(30),
 				},
 				Kveld: models.Artskode{
 					Sum:   decimal.NewFromFloat(3743.24),
-					Hours: 20,
+					Hours: decimal.NewFromInt(20),
 				},
 				Dag: models.Artskode{
 					Sum:   decimal.NewFromFloat(4508.51),
-					Hours: 33,
+					Hours: decimal.NewFromInt(33),
 				},
 				Helg: models.Artskode{
 					Sum:   decimal.NewFromFloat(9327.78),
-					Hours: 48,
+					Hours: decimal.NewFromInt(48),
 				},
 				Skift: models.Artskode{
 					Sum:   decimal.NewFromFloat(100),
-					Hours: 20,
+					Hours: decimal.NewFromInt(20),
 				},
 			},
 		},
@@ -1033,11 +1110,11 @@ func TestHoursGuarddutySalary(t *testing.T) {
 			want: models.Artskoder{
 				Helg: models.Artskode{
 					Sum:   decimal.NewFromFloat(3366.59),
-					Hours: 24,
+					Hours: decimal.NewFromInt(24),
 				},
 				Utrykning: models.Artskode{
 					Sum:   decimal.NewFromInt(130),
-					Hours: 2,
+					Hours: decimal.NewFromInt(2),
 				},
 			},
 			wantErr: false,
@@ -1073,28 +1150,53 @@ func TestHoursGuarddutySalary(t *testing.T) {
 			want: models.Artskoder{
 				Morgen: models.Artskode{
 					Sum:   decimal.NewFromFloat(798.65),
-					Hours: 6,
+					Hours: decimal.NewFromInt(6),
 				},
 				Kveld: models.Artskode{
 					Sum:   decimal.NewFromFloat(532.43),
-					Hours: 4,
+					Hours: decimal.NewFromInt(4),
 				},
 				Dag: models.Artskode{
 					Sum:   decimal.NewFromFloat(672.57),
-					Hours: 7,
+					Hours: decimal.NewFromInt(7),
 				},
 				Helg: models.Artskode{},
 				Skift: models.Artskode{
 					Sum:   decimal.NewFromInt(20),
-					Hours: 4,
+					Hours: decimal.NewFromInt(4),
 				},
 				Utrykning: models.Artskode{
 					Sum:   decimal.NewFromInt(50),
-					Hours: 2,
+					Hours: decimal.NewFromInt(2),
 				},
 			},
 			wantErr: false,
 		},
+		{
+			name: "Vakt over midnatt til en dag som mangler i timelisten",
+			args: args{
+				timesheet: map[string]models.TimeSheet{
+					"2026-01-27": {
+						Date:          time.Date(2026, 1, 27, 0, 0, 0, 0, time.UTC),
+						WorkingHours:  7.5,
+						WorkingDay:    "Virkedag",
+						FormName:      "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Salary:        decimal.NewFromInt(500_000),
+						Stillingskode: "1364",
+					},
+				},
+				guardPeriod: map[string][]models.Period{
+					"2026-01-27": {
+						{
+							Begin: time.Date(2026, 1, 27, 16, 0, 0, 0, time.UTC),
+							End:   time.Date(2026, 1, 28, 8, 0, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			want:    models.Artskoder{},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1111,15 +1213,9 @@ func TestHoursGuarddutySalary(t *testing.T) {
 				ApproverID:   "M654321",
 				ApproverName: "Kalpana, Bran",
 				Timesheet:    tt.args.timesheet,
-				Satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 			}
 
-			got, err := GuarddutySalary(vaktplan, minWinTid)
+			got, err := GuarddutySalary(vaktplan, minWinTid, testVersions, Options{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GuarddutySalary() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1152,8 +1248,8 @@ func TestCalculateIsEqualForHolidayOnSaturdayAndRegularSaturday(t *testing.T) {
 			IsWeekend:    true,
 		},
 	}
-	kronetillegg.Calculate(holidayMinutes, satser, holidayPayroll)
-	overtime.Calculate(holidayMinutes, salary, holidayPayroll)
+	kronetillegg.Calculate(holidayMinutes, satser, testRules.Kronetillegg, testRules.Rounding, holidayPayroll)
+	overtime.Calculate(holidayMinutes, salary, testRules.Overtime.AnnualHours, testRules.Overtime, testRules.Rounding, holidayPayroll)
 
 	regularPayroll := &models.Payroll{}
 	regularMinutes := map[string]models.GuardDuty{
@@ -1166,8 +1262,8 @@ func TestCalculateIsEqualForHolidayOnSaturdayAndRegularSaturday(t *testing.T) {
 			IsWeekend:    true,
 		},
 	}
-	kronetillegg.Calculate(regularMinutes, satser, regularPayroll)
-	overtime.Calculate(regularMinutes, salary, regularPayroll)
+	kronetillegg.Calculate(regularMinutes, satser, testRules.Kronetillegg, testRules.Rounding, regularPayroll)
+	overtime.Calculate(regularMinutes, salary, testRules.Overtime.AnnualHours, testRules.Overtime, testRules.Rounding, regularPayroll)
 
 	if diff := cmp.Diff(holidayPayroll.Artskoder, regularPayroll.Artskoder); diff != "" {
 		t.Errorf("Calculate() holiday on saturday did not return the same as a normal saturday")
@@ -1196,8 +1292,8 @@ func TestCalculateIsNotEqualForHolidayOnMondayAndRegularMonday(t *testing.T) {
 			Skifttillegg:  240,
 		},
 	}
-	kronetillegg.Calculate(holidayMinutes, satser, holidayPayroll)
-	overtime.Calculate(holidayMinutes, salary, holidayPayroll)
+	kronetillegg.Calculate(holidayMinutes, satser, testRules.Kronetillegg, testRules.Rounding, holidayPayroll)
+	overtime.Calculate(holidayMinutes, salary, testRules.Overtime.AnnualHours, testRules.Overtime, testRules.Rounding, holidayPayroll)
 
 	regularPayroll := &models.Payroll{}
 	regularMinutes := map[string]models.GuardDuty{
@@ -1209,11 +1305,174 @@ func TestCalculateIsNotEqualForHolidayOnMondayAndRegularMonday(t *testing.T) {
 			Skifttillegg: 240,
 		},
 	}
-	kronetillegg.Calculate(regularMinutes, satser, regularPayroll)
-	overtime.Calculate(regularMinutes, salary, regularPayroll)
+	kronetillegg.Calculate(regularMinutes, satser, testRules.Kronetillegg, testRules.Rounding, regularPayroll)
+	overtime.Calculate(regularMinutes, salary, testRules.Overtime.AnnualHours, testRules.Overtime, testRules.Rounding, regularPayroll)
 
 	if diff := cmp.Diff(holidayPayroll.Artskoder, regularPayroll.Artskoder); diff == "" {
 		t.Errorf("Calculate() holiday on monday returns the same as a normal monday")
 		t.Errorf("Calculate() mismatch (-want +got):\n%s", diff)
 	}
 }
+
+func FuzzCalculateMinutesToBePaid(f *testing.F) {
+	f.Add(uint16(0), uint16(0), uint16(1440), []byte{80, 78})
+	f.Add(uint16(5), uint16(960), uint16(480), []byte{80, 80, 200, 20})
+	f.Add(uint16(357), uint16(0), uint16(1440), []byte{})
+	f.Add(uint16(100), uint16(600), uint16(100), []byte{50, 200, 100, 100})
+
+	formNames := []string{"BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)", "Helligdag", "Julaften 0800-1200 *", "Deltid 1200-1600"}
+
+	f.Fuzz(func(t *testing.T, day, begin, length uint16, data []byte) {
+		date := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(day%365))
+		if calculateDaylightSavingTimeModifier([]models.Period{{Begin: date, End: date.Add(24 * time.Hour)}}, date) != 0 {
+			// Dagene klokka stilles har en time mer eller mindre enn det vaktplanen sier
+			return
+		}
+
+		period := models.Period{Begin: date.Add(time.Duration(begin%1440) * time.Minute)}
+		period.End = period.Begin.Add(time.Duration(int(length)%(1440-int(begin%1440)+1)) * time.Minute)
+
+		// Hvert par med bytes er en stempling, der første byte er start og andre byte er lengden i 6 minutter
+		var clockings []models.Clocking
+		for i := 0; i+1 < len(data); i += 2 {
+			in := date.Add(time.Duration(int(data[i])*6%1440) * time.Minute)
+			out := in.Add(time.Duration(data[i+1]) * 6 * time.Minute)
+			if midnight := date.Add(24 * time.Hour); out.After(midnight) {
+				out = midnight
+			}
+			clockings = append(clockings, models.Clocking{In: in, Out: out})
+		}
+
+		key := date.Format(VaktorDateFormat)
+		schedule := map[string][]models.Period{key: {period}}
+		timesheet := map[string]models.TimeSheet{
+			key: {
+				Date:         date,
+				WorkingHours: 7.75,
+				FormName:     formNames[int(day)%len(formNames)],
+				Clockings:    clockings,
+			},
+		}
+
+		got, err := calculateMinutesToBePaid(schedule, timesheet, testRules)
+		if err != nil {
+			t.Fatalf("calculateMinutesToBePaid() returned an error: %v", err)
+		}
+
+		duty := got[key]
+		scheduled := int64(period.End.Sub(period.Begin) / time.Minute)
+		for name, minutes := range map[string]int64{
+			"Hvilende0006":  duty.Hvilende0006,
+			"Hvilende0620":  duty.Hvilende0620,
+			"Hvilende2000":  duty.Hvilende2000,
+			"Helligdag0620": duty.Helligdag0620,
+			"Helgetillegg":  duty.Helgetillegg,
+			"Skifttillegg":  duty.Skifttillegg,
+		} {
+			if minutes < 0 {
+				t.Errorf("%v is negative (%v) for %v with %v", name, minutes, period, clockings)
+			}
+		}
+
+		paid := duty.Hvilende0006 + duty.Hvilende0620 + duty.Hvilende2000 + duty.Helligdag0620
+		if paid > scheduled {
+			t.Errorf("paid %v minutes, but only scheduled %v minutes for %v with %v", paid, scheduled, period, clockings)
+		}
+		if duty.Helgetillegg > scheduled || duty.Skifttillegg > scheduled {
+			t.Errorf("paid more helg/skift than scheduled %v minutes: %+v", scheduled, duty)
+		}
+
+		slices.Reverse(clockings)
+		reversed, err := calculateMinutesToBePaid(schedule, timesheet, testRules)
+		if err != nil {
+			t.Fatalf("calculateMinutesToBePaid() returned an error: %v", err)
+		}
+		if diff := cmp.Diff(got, reversed); diff != "" {
+			t.Errorf("calculateMinutesToBePaid() depends on the order of the clockings (-original +reversed):\n%s", diff)
+		}
+	})
+}
+
+func TestNormalizeSchedule(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule map[string][]models.Period
+		want     map[string][]models.Period
+		wantErr  bool
+	}{
+		{
+			name: "periode over midnatt blir delt",
+			schedule: map[string][]models.Period{
+				"2022-10-05": {{Begin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 6, 8, 0, 0, 0, time.UTC)}},
+			},
+			want: map[string][]models.Period{
+				"2022-10-05": {{Begin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC)}},
+				"2022-10-06": {{Begin: time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 6, 8, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name: "periode over nyttår blir delt",
+			schedule: map[string][]models.Period{
+				"2022-12-31": {{Begin: time.Date(2022, 12, 31, 12, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)}},
+			},
+			want: map[string][]models.Period{
+				"2022-12-31": {{Begin: time.Date(2022, 12, 31, 12, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}},
+				"2023-01-01": {{Begin: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 12, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name: "periode lagt under feil dato",
+			schedule: map[string][]models.Period{
+				"2022-10-05": {{Begin: time.Date(2022, 10, 6, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 6, 20, 0, 0, 0, time.UTC)}},
+			},
+			want: map[string][]models.Period{
+				"2022-10-06": {{Begin: time.Date(2022, 10, 6, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 6, 20, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name: "overlappende perioder blir slått sammen",
+			schedule: map[string][]models.Period{
+				"2022-10-05": {
+					{Begin: time.Date(2022, 10, 5, 6, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 5, 9, 0, 0, 0, time.UTC)},
+					{Begin: time.Date(2022, 10, 5, 8, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 5, 10, 0, 0, 0, time.UTC)},
+					{Begin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 5, 20, 0, 0, 0, time.UTC)},
+				},
+			},
+			want: map[string][]models.Period{
+				"2022-10-05": {
+					{Begin: time.Date(2022, 10, 5, 6, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 5, 10, 0, 0, 0, time.UTC)},
+					{Begin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 5, 20, 0, 0, 0, time.UTC)},
+				},
+			},
+		},
+		{
+			name: "tidssone blir gjort om til norsk veggklokke",
+			schedule: map[string][]models.Period{
+				"2022-10-05": {{Begin: time.Date(2022, 10, 5, 14, 0, 0, 0, time.FixedZone("", 0)), End: time.Date(2022, 10, 5, 22, 0, 0, 0, time.FixedZone("", 0))}},
+			},
+			want: map[string][]models.Period{
+				"2022-10-05": {{Begin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name: "periode som slutter før den starter",
+			schedule: map[string][]models.Period{
+				"2022-10-05": {{Begin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 5, 8, 0, 0, 0, time.UTC)}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeSchedule(tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NormalizeSchedule() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NormalizeSchedule() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -1,18 +1,19 @@
 package calculator
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
 func TestGuarddutySalary(t *testing.T) {
 	type args struct {
-		satser      models.Satser
 		timesheet   map[string]models.TimeSheet
 		guardPeriod map[string][]models.Period
 	}
@@ -29,12 +30,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "døgnvakt",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-03-14": {
 						Date:         time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
@@ -164,12 +159,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "døgnvakt uten stemplinger",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-03-14": {
 						Date:         time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
@@ -274,12 +263,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "døgnvakt med perfekt stempling",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-03-14": {
 						Date:         time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
@@ -409,12 +392,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Utvidet beredskap",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-07-04": {
 						Date:         time.Date(2022, 7, 4, 0, 0, 0, 0, time.UTC),
@@ -676,12 +653,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Vakt ved spesielle hendelser",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-07-16": {
 						Date:         time.Date(2022, 7, 16, 0, 0, 0, 0, time.UTC),
@@ -774,12 +745,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Vakt når klokka stilles til sommertid",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-03-27": {
 						Date:         time.Date(2022, 3, 27, 0, 0, 0, 0, time.UTC),
@@ -806,12 +771,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Vakt når klokka stilles til normaltid",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-10-30": {
 						Date:         time.Date(2022, 10, 30, 0, 0, 0, 0, time.UTC),
@@ -838,12 +797,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Utvidet åpningstid dagen klokka stilles til normaltid",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-10-30": {
 						Date:         time.Date(2022, 10, 30, 0, 0, 0, 0, time.UTC),
@@ -870,12 +823,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Helgevakt med utrykning",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-09-24": {
 						Date:         time.Date(2022, 9, 24, 0, 0, 0, 0, time.UTC),
@@ -920,12 +867,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Helgevakt uten utrykning",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-09-24": {
 						Date:         time.Date(2022, 9, 24, 0, 0, 0, 0, time.UTC),
@@ -966,12 +907,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "vakt en dag med utrykning",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-03-14": {
 						Date:         time.Date(2022, 3, 14, 0, 0, 0, 0, time.UTC),
@@ -1008,12 +943,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "En tilfeldig døgnkontinuerlig vaktuke",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				timesheet: map[string]models.TimeSheet{
 					"2022-10-05": {
 						Date:         time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
@@ -1167,12 +1096,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "Overtid utenfor vaktperioden",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				guardPeriod: map[string][]models.Period{
 					"2023-05-12": {
 						{
@@ -1222,23 +1145,23 @@ func TestGuarddutySalary(t *testing.T) {
 				details: &models.Artskoder{
 					Morgen: models.Artskode{
 						Sum:   decimal.NewFromFloat(798.65),
-						Hours: 6,
+						Hours: decimal.NewFromInt(6),
 					},
 					Kveld: models.Artskode{
 						Sum:   decimal.NewFromFloat(532.43),
-						Hours: 4,
+						Hours: decimal.NewFromInt(4),
 					},
 					Dag: models.Artskode{
 						Sum:   decimal.NewFromFloat(576.49),
-						Hours: 6,
+						Hours: decimal.NewFromInt(6),
 					},
 					Helg: models.Artskode{
 						Sum:   decimal.NewFromFloat(1_693.3),
-						Hours: 12,
+						Hours: decimal.NewFromInt(12),
 					},
 					Skift: models.Artskode{
 						Sum:   decimal.NewFromFloat(20),
-						Hours: 4,
+						Hours: decimal.NewFromInt(4),
 					},
 				},
 			},
@@ -1247,12 +1170,6 @@ func TestGuarddutySalary(t *testing.T) {
 		{
 			name: "To vaktdager med forskjellig lønn",
 			args: args{
-				satser: models.Satser{
-					Helg:    decimal.NewFromInt(65),
-					Dag:     decimal.NewFromInt(15),
-					Natt:    decimal.NewFromInt(25),
-					Utvidet: decimal.NewFromInt(25),
-				},
 				guardPeriod: map[string][]models.Period{
 					"2022-10-05": {
 						{
@@ -1301,19 +1218,19 @@ func TestGuarddutySalary(t *testing.T) {
 				details: &models.Artskoder{
 					Morgen: models.Artskode{
 						Sum:   decimal.NewFromFloat(2213.51),
-						Hours: 12,
+						Hours: decimal.NewFromInt(12),
 					},
 					Kveld: models.Artskode{
 						Sum:   decimal.NewFromFloat(1475.68),
-						Hours: 8,
+						Hours: decimal.NewFromInt(8),
 					},
 					Dag: models.Artskode{
 						Sum:   decimal.NewFromFloat(1630.14),
-						Hours: 12,
+						Hours: decimal.NewFromInt(12),
 					},
 					Skift: models.Artskode{
 						Sum:   decimal.NewFromFloat(40),
-						Hours: 8,
+						Hours: decimal.NewFromInt(8),
 					},
 				},
 			},
@@ -1334,10 +1251,9 @@ func TestGuarddutySalary(t *testing.T) {
 				ApproverID:   "M654321",
 				ApproverName: "Kalpana, Bran",
 				Timesheet:    tt.args.timesheet,
-				Satser:       tt.args.satser,
 			}
 
-			payroll, err := GuarddutySalary(vaktplan, minWinTid)
+			payroll, err := GuarddutySalary(vaktplan, minWinTid, testVersions, Options{})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("GuarddutySalary() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -1366,3 +1282,387 @@ func TestGuarddutySalary(t *testing.T) {
 		})
 	}
 }
+
+func TestGuarddutySalaryWithChangedStillingskode(t *testing.T) {
+	schedule := map[string][]models.Period{
+		"2022-10-05": {
+			{
+				Begin: time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		"2022-10-06": {
+			{
+				Begin: time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 10, 7, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	timesheet := map[string]models.TimeSheet{
+		"2022-10-05": {
+			Date:          time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
+			WorkingHours:  7.75,
+			WorkingDay:    "Virkedag",
+			FormName:      "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+			Salary:        decimal.NewFromInt(725000),
+			Stillingskode: "258",
+			Clockings: []models.Clocking{
+				{
+					In:  time.Date(2022, 10, 5, 8, 0, 0, 0, time.UTC),
+					Out: time.Date(2022, 10, 5, 15, 45, 0, 0, time.UTC),
+				},
+			},
+		},
+		"2022-10-06": {
+			Date:          time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC),
+			WorkingHours:  7.75,
+			WorkingDay:    "Virkedag",
+			FormName:      "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+			Salary:        decimal.NewFromInt(750000),
+			Stillingskode: "1065",
+			Clockings: []models.Clocking{
+				{
+					In:  time.Date(2022, 10, 6, 8, 0, 0, 0, time.UTC),
+					Out: time.Date(2022, 10, 6, 15, 45, 0, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	payroll, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, models.MinWinTid{
+		Timesheet: timesheet,
+	}, testVersions, Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+
+	if payroll.Stillingskode != "1065" {
+		t.Errorf("GuarddutySalary() stillingskode = %v, want 1065", payroll.Stillingskode)
+	}
+
+	if len(payroll.Lines) != 2 {
+		t.Fatalf("GuarddutySalary() got %v lines, want 2", len(payroll.Lines))
+	}
+
+	for _, line := range payroll.Lines {
+		date := "2022-10-05"
+		if line.Stillingskode == "1065" {
+			date = "2022-10-06"
+		}
+
+		single, err := GuarddutySalary(models.Vaktplan{Schedule: map[string][]models.Period{date: schedule[date]}}, models.MinWinTid{
+			Timesheet: map[string]models.TimeSheet{date: timesheet[date]},
+		}, testVersions, Options{})
+		if err != nil {
+			t.Fatalf("GuarddutySalary() returned an error: %v", err)
+		}
+
+		if diff := cmp.Diff(single.Artskoder, line.Artskoder); diff != "" {
+			t.Errorf("GuarddutySalary() line %v mismatch (-want +got):\n%s", line.Stillingskode, diff)
+		}
+	}
+
+	// Kronetillegget for dag blir avrundet per stillingskode (2 x 6t15m), og ikke for hele perioden (12t30m)
+	want := models.Artskoder{
+		Morgen: models.Artskode{Sum: decimal.NewFromFloat(2213.51), Hours: decimal.NewFromInt(12)},
+		Kveld:  models.Artskode{Sum: decimal.NewFromFloat(1475.68), Hours: decimal.NewFromInt(8)},
+		Dag:    models.Artskode{Sum: decimal.NewFromFloat(1615.14), Hours: decimal.NewFromInt(12)},
+		Skift:  models.Artskode{Sum: decimal.NewFromFloat(40), Hours: decimal.NewFromInt(8)},
+	}
+	if diff := cmp.Diff(want, payroll.Artskoder); diff != "" {
+		t.Errorf("GuarddutySalary() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestGuarddutySalaryWithUnsplitPeriod(t *testing.T) {
+
+	timesheet := map[string]models.TimeSheet{}
+	for date := time.Date(2022, 10, 7, 0, 0, 0, 0, time.UTC); date.Before(time.Date(2022, 10, 11, 0, 0, 0, 0, time.UTC)); date = date.AddDate(0, 0, 1) {
+		day := models.TimeSheet{
+			Date:          date,
+			WorkingHours:  0,
+			WorkingDay:    "Lørdag",
+			FormName:      "BV Lørdag IKT",
+			Salary:        decimal.NewFromInt(725000),
+			Stillingskode: "258",
+		}
+		if !isWeekend(date) {
+			day.WorkingHours = 7.75
+			day.WorkingDay = "Virkedag"
+			day.FormName = "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)"
+			day.Clockings = []models.Clocking{
+				{
+					In:  time.Date(date.Year(), date.Month(), date.Day(), 8, 0, 0, 0, time.UTC),
+					Out: time.Date(date.Year(), date.Month(), date.Day(), 15, 45, 0, 0, time.UTC),
+				},
+			}
+		}
+		timesheet[date.Format(VaktorDateFormat)] = day
+	}
+	minWinTid := models.MinWinTid{
+		Timesheet: timesheet,
+	}
+
+	split := map[string][]models.Period{
+		"2022-10-07": {{Begin: time.Date(2022, 10, 7, 15, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 8, 0, 0, 0, 0, time.UTC)}},
+		"2022-10-08": {{Begin: time.Date(2022, 10, 8, 0, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 9, 0, 0, 0, 0, time.UTC)}},
+		"2022-10-09": {{Begin: time.Date(2022, 10, 9, 0, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC)}},
+		"2022-10-10": {{Begin: time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 10, 8, 0, 0, 0, time.UTC)}},
+	}
+	want, err := GuarddutySalary(models.Vaktplan{Schedule: split}, minWinTid, testVersions, Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		schedule map[string][]models.Period
+	}{
+		{
+			name: "fredag 15:00 til mandag 08:00 i en periode",
+			schedule: map[string][]models.Period{
+				"2022-10-07": {{Begin: time.Date(2022, 10, 7, 15, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 10, 8, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name: "samme periode med norsk tidssone",
+			schedule: map[string][]models.Period{
+				"2022-10-07": {{Begin: time.Date(2022, 10, 7, 13, 0, 0, 0, time.UTC).In(oslo), End: time.Date(2022, 10, 10, 6, 0, 0, 0, time.UTC).In(oslo)}},
+			},
+		},
+		{
+			name: "overlappende perioder",
+			schedule: map[string][]models.Period{
+				"2022-10-07": {{Begin: time.Date(2022, 10, 7, 15, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 9, 12, 0, 0, 0, time.UTC)}},
+				"2022-10-09": {{Begin: time.Date(2022, 10, 8, 12, 0, 0, 0, time.UTC), End: time.Date(2022, 10, 10, 8, 0, 0, 0, time.UTC)}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GuarddutySalary(models.Vaktplan{Schedule: tt.schedule}, minWinTid, testVersions, Options{})
+			if err != nil {
+				t.Fatalf("GuarddutySalary() returned an error: %v", err)
+			}
+
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("GuarddutySalary() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGuarddutySalaryPerMonth(t *testing.T) {
+	// Vakten er lagt inn som én periode over månedsskiftet
+	schedule := map[string][]models.Period{
+		"2022-10-31": {
+			{
+				Begin: time.Date(2022, 10, 31, 0, 0, 0, 0, time.UTC),
+				End:   time.Date(2022, 11, 2, 0, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	timesheet := map[string]models.TimeSheet{}
+	for _, date := range []time.Time{time.Date(2022, 10, 31, 0, 0, 0, 0, time.UTC), time.Date(2022, 11, 1, 0, 0, 0, 0, time.UTC)} {
+		timesheet[date.Format(VaktorDateFormat)] = models.TimeSheet{
+			Date:          date,
+			WorkingHours:  7.75,
+			WorkingDay:    "Virkedag",
+			FormName:      "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+			Salary:        decimal.NewFromInt(725000),
+			Stillingskode: "258",
+			Clockings: []models.Clocking{
+				{
+					In:  date.Add(8 * time.Hour),
+					Out: date.Add(15*time.Hour + 45*time.Minute),
+				},
+			},
+		}
+	}
+	minWinTid := models.MinWinTid{
+		Timesheet: timesheet,
+	}
+
+	whole, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, minWinTid, testVersions, Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+	if len(whole.Lines) != 0 {
+		t.Errorf("GuarddutySalary() got %v lines without PerMonth, want 0", len(whole.Lines))
+	}
+
+	payroll, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, minWinTid, testVersions, Options{PerMonth: true})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+
+	if payroll.Stillingskode != "258" {
+		t.Errorf("GuarddutySalary() stillingskode = %v, want 258", payroll.Stillingskode)
+	}
+
+	var months []string
+	for _, line := range payroll.Lines {
+		months = append(months, line.Month)
+	}
+	if diff := cmp.Diff([]string{"2022-10", "2022-11"}, months); diff != "" {
+		t.Fatalf("GuarddutySalary() months mismatch (-want +got):\n%s", diff)
+	}
+
+	var total models.Artskoder
+	for _, line := range payroll.Lines {
+		date := "2022-10-31"
+		if line.Month == "2022-11" {
+			date = "2022-11-01"
+		}
+
+		single, err := GuarddutySalary(models.Vaktplan{Schedule: map[string][]models.Period{
+			date: {{Begin: timesheet[date].Date, End: timesheet[date].Date.AddDate(0, 0, 1)}},
+		}}, models.MinWinTid{
+			Timesheet: map[string]models.TimeSheet{date: timesheet[date]},
+		}, testVersions, Options{})
+		if err != nil {
+			t.Fatalf("GuarddutySalary() returned an error: %v", err)
+		}
+
+		if diff := cmp.Diff(single.Artskoder, line.Artskoder); diff != "" {
+			t.Errorf("GuarddutySalary() line %v mismatch (-want +got):\n%s", line.Month, diff)
+		}
+		total = total.Add(line.Artskoder)
+	}
+
+	if diff := cmp.Diff(total, payroll.Artskoder); diff != "" {
+		t.Errorf("GuarddutySalary() total is not the sum of the lines (-lines +got):\n%s", diff)
+	}
+}
+
+func TestGuarddutySalaryDays(t *testing.T) {
+	schedule := map[string][]models.Period{}
+	timesheet := map[string]models.TimeSheet{}
+	for _, date := range []time.Time{time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC), time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC)} {
+		day := date.Format(VaktorDateFormat)
+		schedule[day] = []models.Period{{Begin: date, End: date.AddDate(0, 0, 1)}}
+		timesheet[day] = models.TimeSheet{
+			Date:          date,
+			WorkingHours:  7.75,
+			WorkingDay:    "Virkedag",
+			FormName:      "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+			Salary:        decimal.NewFromInt(725000),
+			Stillingskode: "258",
+			Clockings: []models.Clocking{
+				{
+					In:  date.Add(8 * time.Hour),
+					Out: date.Add(15*time.Hour + 45*time.Minute),
+				},
+			},
+		}
+	}
+
+	want, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, models.MinWinTid{Timesheet: timesheet}, testVersions, Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+
+	// Samme lønn skrevet med desimaler skal prises likt
+	changed := timesheet["2022-10-06"]
+	changed.Salary = decimal.RequireFromString("725000.00")
+	timesheet["2022-10-06"] = changed
+
+	got, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, models.MinWinTid{Timesheet: timesheet}, testVersions, Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(want.Artskoder, got.Artskoder); diff != "" {
+		t.Errorf("GuarddutySalary() mismatch for the same salary (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want.Residuals, got.Residuals); diff != "" {
+		t.Errorf("GuarddutySalary() residuals mismatch for the same salary (-want +got):\n%s", diff)
+	}
+
+	wantDays := []models.PricedDay{
+		{
+			Date:          "2022-10-05",
+			Salary:        decimal.NewFromInt(725000),
+			Stillingskode: "258",
+			AnnualHours:   1850,
+			Satser:        testRules.Satser,
+			RulesVersion:  testRules.Version,
+		},
+		{
+			Date:          "2022-10-06",
+			Salary:        decimal.RequireFromString("725000.00"),
+			Stillingskode: "258",
+			AnnualHours:   1850,
+			Satser:        testRules.Satser,
+			RulesVersion:  testRules.Version,
+		},
+	}
+	if diff := cmp.Diff(wantDays, got.Days); diff != "" {
+		t.Errorf("GuarddutySalary() days mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// changedSatser er to versjoner av reglene, der satsen for natt er natt fra 6. oktober 2022
+func changedSatser(t *testing.T, natt int) rules.Versions {
+	t.Helper()
+
+	versions, err := rules.Parse([]byte(fmt.Sprintf(`{"versjoner": [
+		{"gyldig_fra": "2021-01-01", "versjon": "2021-01", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+			"vaktvinduer": [{"type": "hvilende2000", "dager": "alle", "fra": "20:00", "til": "24:00"}],
+			"kronetillegg": [{"typer": ["hvilende2000"], "dager": "hverdag", "artskode": "kveld", "sats": "natt"}]},
+		{"gyldig_fra": "2022-10-06", "versjon": "2022-10", "satser": {"0620": 15, "2006": %v, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+			"vaktvinduer": [{"type": "hvilende2000", "dager": "alle", "fra": "20:00", "til": "24:00"}],
+			"kronetillegg": [{"typer": ["hvilende2000"], "dager": "hverdag", "artskode": "kveld", "sats": "natt"}]}
+	]}`, natt)))
+	if err != nil {
+		t.Fatalf("Parse() returned an error: %v", err)
+	}
+
+	return versions
+}
+
+func TestGuarddutySalaryWithChangedSatser(t *testing.T) {
+	schedule := map[string][]models.Period{}
+	timesheet := map[string]models.TimeSheet{}
+	for _, date := range []time.Time{time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC), time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC)} {
+		day := date.Format(VaktorDateFormat)
+		schedule[day] = []models.Period{{Begin: date.Add(20 * time.Hour), End: date.AddDate(0, 0, 1)}}
+		timesheet[day] = models.TimeSheet{
+			Date:          date,
+			WorkingHours:  7.75,
+			WorkingDay:    "Virkedag",
+			FormName:      "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+			Salary:        decimal.NewFromInt(725000),
+			Stillingskode: "258",
+			Clockings:     []models.Clocking{},
+		}
+	}
+
+	unchanged, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, models.MinWinTid{Timesheet: timesheet}, changedSatser(t, 25), Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+
+	payroll, err := GuarddutySalary(models.Vaktplan{Schedule: schedule}, models.MinWinTid{Timesheet: timesheet}, changedSatser(t, 30), Options{})
+	if err != nil {
+		t.Fatalf("GuarddutySalary() returned an error: %v", err)
+	}
+
+	// Satsen for natt går opp fra 25 til 30 kroner timen den andre dagen, så de fire timene den dagen gir 20 kroner mer
+	want := models.Artskode{
+		Sum:   unchanged.Artskoder.Kveld.Sum.Add(decimal.NewFromInt(4 * 5)),
+		Hours: decimal.NewFromInt(8),
+	}
+	if diff := cmp.Diff(want, payroll.Artskoder.Kveld); diff != "" {
+		t.Errorf("GuarddutySalary() mismatch (-want +got):\n%s", diff)
+	}
+	if payroll.RulesVersion != "2021-01, 2022-10" {
+		t.Errorf("GuarddutySalary() RulesVersion = %v, want 2021-01, 2022-10", payroll.RulesVersion)
+	}
+
+	wantNatt := []decimal.Decimal{decimal.NewFromInt(25), decimal.NewFromInt(30)}
+	for i, day := range payroll.Days {
+		if !day.Satser.Natt.Equal(wantNatt[i]) {
+			t.Errorf("GuarddutySalary() natt for %v = %v, want %v", day.Date, day.Satser.Natt, wantNatt[i])
+		}
+	}
+}
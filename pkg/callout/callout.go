@@ -3,67 +3,157 @@ package callout
 import (
 	"time"
 
+	"github.com/navikt/vaktor-lonn/pkg/intervals"
 	"github.com/navikt/vaktor-lonn/pkg/models"
-	"github.com/navikt/vaktor-lonn/pkg/ranges"
-	"github.com/shopspring/decimal"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 )
 
-func Calculate(schedule map[string][]models.Period, timesheet map[string]models.TimeSheet, satser models.Satser, payroll *models.Payroll) {
-	minutesInHour := decimal.NewFromInt(60)
-	guardMinutes := models.GuardDuty{}
+const dateFormat = "2006-01-02"
 
-	for day, sheet := range timesheet {
-		date := sheet.Date
+// Calculate legger til utrykningstillegg for overtid som er merket som beredskapsvakt. Tidsrommene som gir
+// tillegg, og på hvilken artskode og sats, er bestemt av reglene. Hvert minutt blir utbetalt etter den første
+// regelen som dekker det. Utrykninger som ligger tett blir slått sammen, og korte utrykninger blir utbetalt med
+// minste utrykning så langt vakten varer. Timene blir avrundet for hver regel for seg, etter avrundingen for
+// artskoden, og hver utrykning blir rapportert for seg i utbetalingen.
+func Calculate(schedule map[string][]models.Period, timesheet map[string]models.TimeSheet, satser models.Satser, r rules.Rules, payroll *models.Payroll) {
+	calloutMinutes := make([]map[string]int64, len(r.Callouts))
+	for i := range calloutMinutes {
+		calloutMinutes[i] = make(map[string]int64)
+	}
+
+	var guardDuty intervals.Set
+	for _, periods := range schedule {
+		for _, period := range periods {
+			guardDuty = guardDuty.Union(intervals.Between(period.Begin, period.End))
+		}
+	}
+
+	var overtime []intervals.Interval
+	for _, sheet := range timesheet {
 		for _, clocking := range sheet.Clockings {
-			if clocking.OtG {
-				if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
-					for _, guardDutyPeriod := range schedule[day] {
-						workRange := ranges.FromTime(clocking.In, clocking.Out)
-
-						dutyRange := ranges.CreateForPeriod(guardDutyPeriod, models.Period{
-							Begin: time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC),
-							End:   time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Add(24 * time.Hour),
-						})
-
-						minutesWithGuardDuty := ranges.CalculateMinutesOverlapping(workRange, *dutyRange)
-						guardMinutes.Helgetillegg += minutesWithGuardDuty
-					}
-				} else {
-					for _, guardDutyPeriod := range schedule[day] {
-						workRange := ranges.FromTime(clocking.In, clocking.Out)
-
-						dutyRangeEarly := ranges.CreateForPeriod(guardDutyPeriod, models.Period{
-							Begin: time.Date(date.Year(), date.Month(), date.Day(), 6, 0, 0, 0, time.UTC),
-							End:   time.Date(date.Year(), date.Month(), date.Day(), 7, 0, 0, 0, time.UTC),
-						})
-
-						if dutyRangeEarly != nil {
-							minutesWithGuardDuty := ranges.CalculateMinutesOverlapping(workRange, *dutyRangeEarly)
-							guardMinutes.Skifttillegg += minutesWithGuardDuty
-						}
-
-						dutyRangeLate := ranges.CreateForPeriod(guardDutyPeriod, models.Period{
-							Begin: time.Date(date.Year(), date.Month(), date.Day(), 17, 0, 0, 0, time.UTC),
-							End:   time.Date(date.Year(), date.Month(), date.Day(), 20, 0, 0, 0, time.UTC),
-						})
-
-						if dutyRangeLate != nil {
-							minutesWithGuardDuty := ranges.CalculateMinutesOverlapping(workRange, *dutyRangeLate)
-							guardMinutes.Skifttillegg += minutesWithGuardDuty
-						}
-					}
+			if !clocking.OtG {
+				continue
+			}
+
+			overtime = append(overtime, intervals.Interval{
+				Begin: clocking.In.Truncate(time.Minute),
+				End:   clocking.Out.Truncate(time.Minute),
+			})
+		}
+	}
+
+	var alreadyPaid intervals.Set
+	for _, callout := range mergeCallouts(intervals.New(overtime...), r.CalloutMergeGap) {
+		worked := callout.Intersect(guardDuty)
+		if worked.IsEmpty() {
+			continue
+		}
+
+		paid := worked.Union(minimumCallout(worked, r.CalloutMinimum).Intersect(guardDuty)).Subtract(alreadyPaid)
+		alreadyPaid = alreadyPaid.Union(paid)
+
+		workedIntervals := worked.Intervals()
+		breakdown := models.Callout{
+			Begin:          workedIntervals[0].Begin,
+			End:            workedIntervals[len(workedIntervals)-1].End,
+			Minutes:        worked.Minutes(),
+			MinimumMinutes: paid.Subtract(worked).Minutes(),
+		}
+
+		for _, part := range paid.SplitAtMidnight() {
+			date := time.Date(part.Begin.Year(), part.Begin.Month(), part.Begin.Day(), 0, 0, 0, 0, time.UTC)
+			day := date.Format(dateFormat)
+			sheet := timesheet[day]
+
+			remaining := intervals.New(part)
+			for i, rule := range r.Callouts {
+				covered := remaining.Intersect(ruleWindows(rule, date, sheet.FormName, r))
+				minutes := covered.Minutes()
+				if minutes == 0 {
+					continue
 				}
+
+				calloutMinutes[i][day] += minutes
+				breakdown.Add(rule.Artskode, rule.Sats, minutes)
+				remaining = remaining.Subtract(covered)
 			}
+			breakdown.UnpaidMinutes += remaining.Minutes()
 		}
+
+		payroll.Callouts = append(payroll.Callouts, breakdown)
 	}
 
-	hours := decimal.NewFromInt(int64(guardMinutes.Helgetillegg)).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Utrykning.Hours = payroll.Artskoder.Utrykning.Hours + hours.IntPart()
-	compensation := hours.Mul(satser.Helg).Round(2)
-	payroll.Artskoder.Utrykning.Sum = payroll.Artskoder.Utrykning.Sum.Add(compensation)
+	for i, rule := range r.Callouts {
+		sats, _ := satser.Sats(rule.Sats)
+		artskode, _ := payroll.Artskoder.Artskode(rule.Artskode)
 
-	hours = decimal.NewFromInt(int64(guardMinutes.Skifttillegg)).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Utrykning.Hours = payroll.Artskoder.Utrykning.Hours + hours.IntPart()
-	compensation = hours.Mul(satser.Utvidet).Round(2)
-	payroll.Artskoder.Utrykning.Sum = payroll.Artskoder.Utrykning.Sum.Add(compensation)
+		var minutesPerDay []int64
+		for _, minutes := range calloutMinutes[i] {
+			minutesPerDay = append(minutesPerDay, minutes)
+		}
+
+		hours, residual := r.Rounding.For(rule.Artskode).Hours(minutesPerDay)
+		payroll.Residuals.Add(models.ResidualCallout, rule.Artskode, residual)
+		artskode.Hours = artskode.Hours.Add(hours)
+		compensation := hours.Mul(sats).Round(2)
+		artskode.Sum = artskode.Sum.Add(compensation)
+	}
+}
+
+// mergeCallouts slår sammen overtid med høyst gap minutter mellom seg til en utrykning
+func mergeCallouts(overtime intervals.Set, gap int64) []intervals.Set {
+	var callouts []intervals.Set
+	var current []intervals.Interval
+	for _, interval := range overtime.Intervals() {
+		if len(current) > 0 && interval.Begin.Sub(current[len(current)-1].End) > time.Duration(gap)*time.Minute {
+			callouts = append(callouts, intervals.New(current...))
+			current = nil
+		}
+		current = append(current, interval)
+	}
+	if len(current) > 0 {
+		callouts = append(callouts, intervals.New(current...))
+	}
+
+	return callouts
+}
+
+// minimumCallout returnerer tiden som må legges til etter utrykningen for at den skal vare minst minimum minutter
+func minimumCallout(worked intervals.Set, minimum int64) intervals.Set {
+	missing := time.Duration(minimum)*time.Minute - worked.Duration()
+	if missing <= 0 {
+		return intervals.Set{}
+	}
+
+	workedIntervals := worked.Intervals()
+	end := workedIntervals[len(workedIntervals)-1].End
+	return intervals.Between(end, end.Add(missing))
+}
+
+// ruleWindows returnerer tidsrommene en regel gir utrykningstillegg for på en dato. Helligdager som starter midt
+// på dagen gir kun tillegg etter at kjernetiden for helligdagen starter.
+func ruleWindows(rule rules.Callout, date time.Time, formName string, r rules.Rules) intervals.Set {
+	var windows []intervals.Interval
+	for _, window := range rule.Windows {
+		period := window.Period(date)
+		windows = append(windows, intervals.Interval{Begin: period.Begin, End: period.End})
+	}
+
+	if rule.Days != rules.Holidays {
+		if !rule.Days.Includes(date) {
+			return intervals.Set{}
+		}
+		return intervals.New(windows...)
+	}
+
+	holiday, ok := r.Holiday(formName)
+	if !ok || !rules.Weekdays.Includes(date) {
+		return intervals.Set{}
+	}
+
+	holidayBegins := date
+	if holiday.Kjernetid != nil {
+		holidayBegins = holiday.Kjernetid.Period(date).Begin
+	}
+	return intervals.New(windows...).Intersect(intervals.Between(holidayBegins, date.AddDate(0, 0, 1)))
 }
@@ -7,9 +7,13 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/uuid"
 	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
+// testRules er reglene fra særavtalen som gjaldt da testene ble skrevet
+var testRules, _ = rules.Default().At(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
 func TestCalculate(t *testing.T) {
 	type args struct {
 		schedule  map[string][]models.Period
@@ -50,7 +54,7 @@ func TestCalculate(t *testing.T) {
 			want: models.Artskoder{
 				Utrykning: models.Artskode{
 					Sum:   decimal.NewFromInt(130),
-					Hours: 2,
+					Hours: decimal.NewFromInt(2),
 				},
 			},
 		},
@@ -90,7 +94,7 @@ func TestCalculate(t *testing.T) {
 			want: models.Artskoder{
 				Utrykning: models.Artskode{
 					Sum:   decimal.NewFromInt(65),
-					Hours: 1,
+					Hours: decimal.NewFromInt(1),
 				},
 			},
 		},
@@ -125,7 +129,7 @@ func TestCalculate(t *testing.T) {
 			want: models.Artskoder{
 				Utrykning: models.Artskode{
 					Sum:   decimal.NewFromInt(0),
-					Hours: 0,
+					Hours: decimal.NewFromInt(0),
 				},
 			},
 		},
@@ -195,7 +199,7 @@ func TestCalculate(t *testing.T) {
 			want: models.Artskoder{
 				Utrykning: models.Artskode{
 					Sum:   decimal.NewFromInt(100),
-					Hours: 4,
+					Hours: decimal.NewFromInt(4),
 				},
 			},
 		},
@@ -245,11 +249,242 @@ func TestCalculate(t *testing.T) {
 		}
 
 		t.Run(tt.name, func(t *testing.T) {
-			Calculate(tt.args.schedule, tt.args.timesheet, satser, payroll)
+			Calculate(tt.args.schedule, tt.args.timesheet, satser, testRules, payroll)
+
+			if diff := cmp.Diff(tt.want, payroll.Artskoder); diff != "" {
+				t.Errorf("Calculate() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCalculate_Callouts(t *testing.T) {
+	// reglene fra særavtalen med minste utrykning, sammenslåing, og tillegg for helligdager og natt i ukedagene
+	r := testRules
+	r.CalloutMinimum = 60
+	r.CalloutMergeGap = 30
+	r.Callouts = append([]rules.Callout{
+		{
+			Days:     rules.Holidays,
+			Windows:  []rules.Window{{Begin: 0, End: rules.Clock(24 * time.Hour)}},
+			Artskode: "utrykning",
+			Sats:     "helg",
+		},
+		{
+			Days: rules.Weekdays,
+			Windows: []rules.Window{
+				{Begin: 0, End: rules.Clock(6 * time.Hour)},
+				{Begin: rules.Clock(20 * time.Hour), End: rules.Clock(24 * time.Hour)},
+			},
+			Artskode: "utrykning",
+			Sats:     "natt",
+		},
+	}, testRules.Callouts...)
+
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	type args struct {
+		schedule  map[string][]models.Period
+		timesheet map[string]models.TimeSheet
+	}
+	tests := []struct {
+		name         string
+		args         args
+		want         models.Artskoder
+		wantCallouts []models.Callout
+	}{
+		{
+			name: "Kort utrykning blir utbetalt med minste utrykning",
+			args: args{
+				schedule: map[string][]models.Period{
+					"2022-10-17": {{Begin: day(2022, 10, 17), End: day(2022, 10, 18)}},
+				},
+				timesheet: map[string]models.TimeSheet{
+					"2022-10-17": {
+						Date:     day(2022, 10, 17),
+						FormName: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Clockings: []models.Clocking{
+							{In: time.Date(2022, 10, 17, 17, 30, 0, 0, time.UTC), Out: time.Date(2022, 10, 17, 17, 50, 0, 0, time.UTC), OtG: true},
+						},
+					},
+				},
+			},
+			want: models.Artskoder{
+				Utrykning: models.Artskode{Sum: decimal.NewFromInt(25), Hours: decimal.NewFromInt(1)},
+			},
+			wantCallouts: []models.Callout{
+				{
+					Begin:          time.Date(2022, 10, 17, 17, 30, 0, 0, time.UTC),
+					End:            time.Date(2022, 10, 17, 17, 50, 0, 0, time.UTC),
+					Minutes:        20,
+					MinimumMinutes: 40,
+					Parts:          []models.CalloutPart{{Artskode: "2685", Sats: "utvidet", Minutes: 60}},
+				},
+			},
+		},
+		{
+			name: "Utrykninger med kort mellomrom blir slått sammen",
+			args: args{
+				schedule: map[string][]models.Period{
+					"2022-10-17": {{Begin: day(2022, 10, 17), End: day(2022, 10, 18)}},
+				},
+				timesheet: map[string]models.TimeSheet{
+					"2022-10-17": {
+						Date:     day(2022, 10, 17),
+						FormName: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Clockings: []models.Clocking{
+							{In: time.Date(2022, 10, 17, 21, 0, 0, 0, time.UTC), Out: time.Date(2022, 10, 17, 21, 20, 0, 0, time.UTC), OtG: true},
+							{In: time.Date(2022, 10, 17, 21, 40, 0, 0, time.UTC), Out: time.Date(2022, 10, 17, 22, 0, 0, 0, time.UTC), OtG: true},
+						},
+					},
+				},
+			},
+			want: models.Artskoder{
+				Utrykning: models.Artskode{Sum: decimal.NewFromInt(25), Hours: decimal.NewFromInt(1)},
+			},
+			wantCallouts: []models.Callout{
+				{
+					Begin:          time.Date(2022, 10, 17, 21, 0, 0, 0, time.UTC),
+					End:            time.Date(2022, 10, 17, 22, 0, 0, 0, time.UTC),
+					Minutes:        40,
+					MinimumMinutes: 20,
+					Parts:          []models.CalloutPart{{Artskode: "2685", Sats: "natt", Minutes: 60}},
+				},
+			},
+		},
+		{
+			name: "Utrykning over midnatt fra fredag til lørdag",
+			args: args{
+				schedule: map[string][]models.Period{
+					"2022-10-21": {{Begin: time.Date(2022, 10, 21, 20, 0, 0, 0, time.UTC), End: day(2022, 10, 22)}},
+					"2022-10-22": {{Begin: day(2022, 10, 22), End: time.Date(2022, 10, 22, 2, 0, 0, 0, time.UTC)}},
+				},
+				timesheet: map[string]models.TimeSheet{
+					"2022-10-21": {
+						Date:     day(2022, 10, 21),
+						FormName: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Clockings: []models.Clocking{
+							{In: time.Date(2022, 10, 21, 23, 0, 0, 0, time.UTC), Out: time.Date(2022, 10, 22, 1, 0, 0, 0, time.UTC), OtG: true},
+						},
+					},
+				},
+			},
+			want: models.Artskoder{
+				Utrykning: models.Artskode{Sum: decimal.NewFromInt(90), Hours: decimal.NewFromInt(2)},
+			},
+			wantCallouts: []models.Callout{
+				{
+					Begin:   time.Date(2022, 10, 21, 23, 0, 0, 0, time.UTC),
+					End:     time.Date(2022, 10, 22, 1, 0, 0, 0, time.UTC),
+					Minutes: 120,
+					Parts: []models.CalloutPart{
+						{Artskode: "2685", Sats: "natt", Minutes: 60},
+						{Artskode: "2685", Sats: "helg", Minutes: 60},
+					},
+				},
+			},
+		},
+		{
+			name: "Utrykning på helligdag",
+			args: args{
+				schedule: map[string][]models.Period{
+					"2022-12-26": {{Begin: day(2022, 12, 26), End: day(2022, 12, 27)}},
+				},
+				timesheet: map[string]models.TimeSheet{
+					"2022-12-26": {
+						Date:     day(2022, 12, 26),
+						FormName: "Helligdag",
+						Clockings: []models.Clocking{
+							{In: time.Date(2022, 12, 26, 10, 0, 0, 0, time.UTC), Out: time.Date(2022, 12, 26, 11, 0, 0, 0, time.UTC), OtG: true},
+						},
+					},
+				},
+			},
+			want: models.Artskoder{
+				Utrykning: models.Artskode{Sum: decimal.NewFromInt(65), Hours: decimal.NewFromInt(1)},
+			},
+			wantCallouts: []models.Callout{
+				{
+					Begin:   time.Date(2022, 12, 26, 10, 0, 0, 0, time.UTC),
+					End:     time.Date(2022, 12, 26, 11, 0, 0, 0, time.UTC),
+					Minutes: 60,
+					Parts:   []models.CalloutPart{{Artskode: "2685", Sats: "helg", Minutes: 60}},
+				},
+			},
+		},
+		{
+			name: "Utrykning på julaften før og etter helligdagen starter",
+			args: args{
+				schedule: map[string][]models.Period{
+					"2021-12-24": {{Begin: day(2021, 12, 24), End: day(2021, 12, 25)}},
+				},
+				timesheet: map[string]models.TimeSheet{
+					"2021-12-24": {
+						Date:     day(2021, 12, 24),
+						FormName: "Julaften 0800-1200 *",
+						Clockings: []models.Clocking{
+							{In: time.Date(2021, 12, 24, 6, 30, 0, 0, time.UTC), Out: time.Date(2021, 12, 24, 13, 0, 0, 0, time.UTC), OtG: true},
+						},
+					},
+				},
+			},
+			want: models.Artskoder{
+				Utrykning: models.Artskode{Sum: decimal.NewFromInt(350), Hours: decimal.NewFromInt(6)},
+			},
+			wantCallouts: []models.Callout{
+				{
+					Begin:         time.Date(2021, 12, 24, 6, 30, 0, 0, time.UTC),
+					End:           time.Date(2021, 12, 24, 13, 0, 0, 0, time.UTC),
+					Minutes:       390,
+					UnpaidMinutes: 60,
+					Parts: []models.CalloutPart{
+						{Artskode: "2685", Sats: "helg", Minutes: 300},
+						{Artskode: "2685", Sats: "utvidet", Minutes: 30},
+					},
+				},
+			},
+		},
+		{
+			name: "Overtid utenfor vakten er ikke utrykning",
+			args: args{
+				schedule: map[string][]models.Period{
+					"2022-10-17": {{Begin: time.Date(2022, 10, 17, 16, 0, 0, 0, time.UTC), End: day(2022, 10, 18)}},
+				},
+				timesheet: map[string]models.TimeSheet{
+					"2022-10-17": {
+						Date:     day(2022, 10, 17),
+						FormName: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Clockings: []models.Clocking{
+							{In: time.Date(2022, 10, 17, 6, 0, 0, 0, time.UTC), Out: time.Date(2022, 10, 17, 7, 0, 0, 0, time.UTC), OtG: true},
+						},
+					},
+				},
+			},
+			want:         models.Artskoder{},
+			wantCallouts: nil,
+		},
+	}
+
+	satser := models.Satser{
+		Helg:    decimal.NewFromInt(65),
+		Dag:     decimal.NewFromInt(15),
+		Natt:    decimal.NewFromInt(25),
+		Utvidet: decimal.NewFromInt(25),
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payroll := &models.Payroll{}
+			Calculate(tt.args.schedule, tt.args.timesheet, satser, r, payroll)
 
 			if diff := cmp.Diff(tt.want, payroll.Artskoder); diff != "" {
 				t.Errorf("Calculate() mismatch (-want +got):\n%s", diff)
 			}
+			if diff := cmp.Diff(tt.wantCallouts, payroll.Callouts); diff != "" {
+				t.Errorf("Calculate() callouts mismatch (-want +got):\n%s", diff)
+			}
 		})
 	}
 }
@@ -0,0 +1,74 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// KeySize er lengden på nøkler til AES-256
+const KeySize = 32
+
+// Cipher krypterer og dekrypterer med AES-256-GCM. Nonce-en legges foran den krypterte teksten.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// ParseKey leser en base64-kodet nøkkel
+func ParseKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key must be %v bytes, got %v", KeySize, len(key))
+	}
+	return key, nil
+}
+
+// NewCipher lager en Cipher med en nøkkel på KeySize byte
+func NewCipher(key []byte) (Cipher, error) {
+	if len(key) != KeySize {
+		return Cipher{}, fmt.Errorf("key must be %v bytes, got %v", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return Cipher{}, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return Cipher{}, err
+	}
+
+	return Cipher{aead: aead}, nil
+}
+
+// Seal krypterer plaintext med en tilfeldig nonce. additionalData blir ikke kryptert, men må være den samme for at
+// teksten skal kunne dekrypteres, slik at den krypterte teksten kan bindes til for eksempel raden den er lagret i.
+func (c Cipher) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generating nonce: %w", err)
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open dekrypterer en tekst kryptert med Seal, med den samme additionalData
+func (c Cipher) Open(sealed, additionalData []byte) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("sealed text is shorter than the nonce")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("opening sealed text: %w", err)
+	}
+
+	return plaintext, nil
+}
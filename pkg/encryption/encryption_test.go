@@ -0,0 +1,90 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func TestCipher(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, KeySize)
+	c, err := NewCipher(key)
+	if err != nil {
+		t.Fatalf("NewCipher() returned an error: %v", err)
+	}
+
+	plaintext := []byte(`{"nav_id":"123456"}`)
+	row := []byte("rad")
+	sealed, err := c.Seal(plaintext, row)
+	if err != nil {
+		t.Fatalf("Seal() returned an error: %v", err)
+	}
+	if bytes.Contains(sealed, plaintext) {
+		t.Errorf("Seal() = %s, contains the plaintext", sealed)
+	}
+
+	again, err := c.Seal(plaintext, row)
+	if err != nil {
+		t.Fatalf("Seal() returned an error: %v", err)
+	}
+	if bytes.Equal(sealed, again) {
+		t.Errorf("Seal() gave the same text twice, want a new nonce each time")
+	}
+
+	opened, err := c.Open(sealed, row)
+	if err != nil {
+		t.Fatalf("Open() returned an error: %v", err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Open() = %s, want %s", opened, plaintext)
+	}
+
+	other, err := NewCipher(bytes.Repeat([]byte{2}, KeySize))
+	if err != nil {
+		t.Fatalf("NewCipher() returned an error: %v", err)
+	}
+	if _, err := other.Open(sealed, row); err == nil {
+		t.Errorf("Open() with another key returned no error")
+	}
+
+	// Teksten er bundet til raden, og kan ikke flyttes til en annen rad
+	if _, err := c.Open(sealed, []byte("en annen rad")); err == nil {
+		t.Errorf("Open() with other additional data returned no error")
+	}
+	if _, err := c.Open(sealed, nil); err == nil {
+		t.Errorf("Open() without additional data returned no error")
+	}
+	if _, err := c.Open(sealed[:4], row); err == nil {
+		t.Errorf("Open() of a truncated text returned no error")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+		wantErr bool
+	}{
+		{
+			name:    "gyldig nøkkel",
+			encoded: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize)),
+		},
+		{
+			name:    "for kort nøkkel",
+			encoded: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 16)),
+			wantErr: true,
+		},
+		{
+			name:    "ikke base64",
+			encoded: "ikke base64!",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKey(tt.encoded); (err != nil) != tt.wantErr {
+				t.Errorf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
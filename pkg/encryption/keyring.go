@@ -0,0 +1,130 @@
+package encryption
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Keyring er nøklene som krypterer datanøklene til hver rad. Nye datanøkler krypteres med den aktive nøkkelen, mens
+// alle nøklene kan dekryptere, slik at en nøkkel kan byttes ut uten at alle radene må krypteres på nytt samtidig.
+type Keyring struct {
+	active  string
+	keys    map[string]Cipher
+	hashKey []byte
+}
+
+// DataKey er nøkkelen som krypterer en rad. Wrapped er datanøkkelen kryptert med nøkkelen KeyID, og er det som
+// lagres sammen med raden.
+type DataKey struct {
+	Cipher
+	KeyID   string
+	Wrapped []byte
+}
+
+// ParseKeys leser nøkler på formen "id:base64,id:base64"
+func ParseKeys(spec string) (map[string][]byte, error) {
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key %q must be on the form id:base64", entry)
+		}
+		if _, ok := keys[id]; ok {
+			return nil, fmt.Errorf("key %q is given twice", id)
+		}
+
+		key, err := ParseKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	return keys, nil
+}
+
+// NewKeyring lager en Keyring der active er nøkkelen nye datanøkler krypteres med, og hashKey er nøkkelen til Hash
+func NewKeyring(keys map[string][]byte, active string, hashKey []byte) (Keyring, error) {
+	if _, ok := keys[active]; !ok {
+		return Keyring{}, fmt.Errorf("active key %q is not among the keys", active)
+	}
+	if len(hashKey) != KeySize {
+		return Keyring{}, fmt.Errorf("hash key must be %v bytes, got %v", KeySize, len(hashKey))
+	}
+
+	keyring := Keyring{
+		active:  active,
+		keys:    make(map[string]Cipher),
+		hashKey: hashKey,
+	}
+	for id, key := range keys {
+		c, err := NewCipher(key)
+		if err != nil {
+			return Keyring{}, fmt.Errorf("key %q: %w", id, err)
+		}
+		keyring.keys[id] = c
+	}
+
+	return keyring, nil
+}
+
+// Active er id-en til nøkkelen nye datanøkler krypteres med
+func (k Keyring) Active() string {
+	return k.active
+}
+
+// NewDataKey lager en ny tilfeldig datanøkkel, kryptert med den aktive nøkkelen og bundet til additionalData
+func (k Keyring) NewDataKey(additionalData []byte) (DataKey, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return DataKey{}, fmt.Errorf("generating data key: %w", err)
+	}
+
+	wrapped, err := k.keys[k.active].Seal(key, additionalData)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	c, err := NewCipher(key)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	return DataKey{Cipher: c, KeyID: k.active, Wrapped: wrapped}, nil
+}
+
+// OpenDataKey dekrypterer en datanøkkel som er kryptert med nøkkelen keyID og bundet til additionalData
+func (k Keyring) OpenDataKey(keyID string, wrapped, additionalData []byte) (DataKey, error) {
+	keyCipher, ok := k.keys[keyID]
+	if !ok {
+		return DataKey{}, fmt.Errorf("unknown key %q", keyID)
+	}
+
+	key, err := keyCipher.Open(wrapped, additionalData)
+	if err != nil {
+		return DataKey{}, fmt.Errorf("opening data key: %w", err)
+	}
+
+	c, err := NewCipher(key)
+	if err != nil {
+		return DataKey{}, err
+	}
+
+	return DataKey{Cipher: c, KeyID: keyID, Wrapped: wrapped}, nil
+}
+
+// Hash er en nøklet hash av en verdi, slik at rader kan slås opp på verdien uten at den lagres i klartekst
+func (k Keyring) Hash(value string) string {
+	mac := hmac.New(sha256.New, k.hashKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
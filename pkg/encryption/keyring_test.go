@@ -0,0 +1,150 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+)
+
+func testKeys(ids ...string) map[string][]byte {
+	keys := make(map[string][]byte)
+	for i, id := range ids {
+		keys[id] = bytes.Repeat([]byte{byte(i + 1)}, KeySize)
+	}
+	return keys
+}
+
+func TestKeyring_DataKey(t *testing.T) {
+	hashKey := bytes.Repeat([]byte{9}, KeySize)
+	old, err := NewKeyring(testKeys("2024"), "2024", hashKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() returned an error: %v", err)
+	}
+
+	row := []byte("rad")
+	dataKey, err := old.NewDataKey(row)
+	if err != nil {
+		t.Fatalf("NewDataKey() returned an error: %v", err)
+	}
+	if dataKey.KeyID != "2024" {
+		t.Errorf("NewDataKey() key id = %v, want 2024", dataKey.KeyID)
+	}
+
+	sealed, err := dataKey.Seal([]byte("a123456"), row)
+	if err != nil {
+		t.Fatalf("Seal() returned an error: %v", err)
+	}
+
+	// Etter at nøkkelen er byttet ut kan eldre rader fortsatt leses, mens nye rader får den nye nøkkelen
+	rotated, err := NewKeyring(testKeys("2024", "2025"), "2025", hashKey)
+	if err != nil {
+		t.Fatalf("NewKeyring() returned an error: %v", err)
+	}
+
+	opened, err := rotated.OpenDataKey(dataKey.KeyID, dataKey.Wrapped, row)
+	if err != nil {
+		t.Fatalf("OpenDataKey() returned an error: %v", err)
+	}
+	plaintext, err := opened.Open(sealed, row)
+	if err != nil {
+		t.Fatalf("Open() returned an error: %v", err)
+	}
+	if string(plaintext) != "a123456" {
+		t.Errorf("Open() = %s, want a123456", plaintext)
+	}
+
+	newKey, err := rotated.NewDataKey(row)
+	if err != nil {
+		t.Fatalf("NewDataKey() returned an error: %v", err)
+	}
+	if newKey.KeyID != "2025" {
+		t.Errorf("NewDataKey() key id = %v, want 2025", newKey.KeyID)
+	}
+
+	if _, err := old.OpenDataKey(newKey.KeyID, newKey.Wrapped, row); err == nil {
+		t.Errorf("OpenDataKey() with an unknown key returned no error")
+	}
+	if _, err := rotated.OpenDataKey("2024", newKey.Wrapped, row); err == nil {
+		t.Errorf("OpenDataKey() with the wrong key returned no error")
+	}
+	if _, err := rotated.OpenDataKey(newKey.KeyID, newKey.Wrapped, []byte("en annen rad")); err == nil {
+		t.Errorf("OpenDataKey() for another row returned no error")
+	}
+}
+
+func TestKeyring_Hash(t *testing.T) {
+	keyring, err := NewKeyring(testKeys("2024"), "2024", bytes.Repeat([]byte{9}, KeySize))
+	if err != nil {
+		t.Fatalf("NewKeyring() returned an error: %v", err)
+	}
+	other, err := NewKeyring(testKeys("2024"), "2024", bytes.Repeat([]byte{8}, KeySize))
+	if err != nil {
+		t.Fatalf("NewKeyring() returned an error: %v", err)
+	}
+
+	if keyring.Hash("a123456") != keyring.Hash("a123456") {
+		t.Errorf("Hash() is not the same for the same ident")
+	}
+	if keyring.Hash("a123456") == keyring.Hash("b123456") {
+		t.Errorf("Hash() is the same for different idents")
+	}
+	if keyring.Hash("a123456") == other.Hash("a123456") {
+		t.Errorf("Hash() is the same with different keys")
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, KeySize))
+	tests := []struct {
+		name    string
+		spec    string
+		wantIDs []string
+		wantErr bool
+	}{
+		{
+			name:    "to nøkler",
+			spec:    "2024:" + key + ", 2025:" + key,
+			wantIDs: []string{"2024", "2025"},
+		},
+		{
+			name:    "mangler id",
+			spec:    key,
+			wantErr: true,
+		},
+		{
+			name:    "samme id to ganger",
+			spec:    "2024:" + key + ",2024:" + key,
+			wantErr: true,
+		},
+		{
+			name:    "ugyldig nøkkel",
+			spec:    "2024:kort",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKeys(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeys() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			for _, id := range tt.wantIDs {
+				if _, ok := got[id]; !ok {
+					t.Errorf("ParseKeys() is missing key %v", id)
+				}
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Errorf("ParseKeys() got %v keys, want %v", len(got), len(tt.wantIDs))
+			}
+		})
+	}
+}
+
+func TestNewKeyring(t *testing.T) {
+	if _, err := NewKeyring(testKeys("2024"), "2025", bytes.Repeat([]byte{9}, KeySize)); err == nil {
+		t.Errorf("NewKeyring() with an unknown active key returned no error")
+	}
+	if _, err := NewKeyring(testKeys("2024"), "2024", nil); err == nil {
+		t.Errorf("NewKeyring() without a hash key returned no error")
+	}
+}
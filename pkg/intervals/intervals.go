@@ -0,0 +1,182 @@
+package intervals
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Interval er et halvåpent tidsrom [Begin, End). Et tidsrom der End ikke er etter Begin er tomt.
+type Interval struct {
+	Begin time.Time
+	End   time.Time
+}
+
+func (i Interval) IsEmpty() bool {
+	return !i.End.After(i.Begin)
+}
+
+func (i Interval) Duration() time.Duration {
+	if i.IsEmpty() {
+		return 0
+	}
+	return i.End.Sub(i.Begin)
+}
+
+func (i Interval) String() string {
+	return fmt.Sprintf("[%v...%v)", i.Begin.Format(time.DateTime), i.End.Format(time.DateTime))
+}
+
+// Set er en mengde med tidsrom. Tidsrommene er alltid sortert, ikke-tomme og uten overlapp,
+// slik at ingen tid telles to ganger. Nullverdien er en tom mengde.
+type Set struct {
+	intervals []Interval
+}
+
+// New lager en mengde av tidsrommene. Tidsrom som overlapper eller møtes blir slått sammen.
+func New(intervals ...Interval) Set {
+	sorted := make([]Interval, 0, len(intervals))
+	for _, interval := range intervals {
+		if !interval.IsEmpty() {
+			sorted = append(sorted, interval)
+		}
+	}
+
+	slices.SortFunc(sorted, func(a, b Interval) int {
+		return a.Begin.Compare(b.Begin)
+	})
+
+	var merged []Interval
+	for _, interval := range sorted {
+		if last := len(merged) - 1; last >= 0 && !interval.Begin.After(merged[last].End) {
+			if interval.End.After(merged[last].End) {
+				merged[last].End = interval.End
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+
+	return Set{intervals: merged}
+}
+
+// Between er en snarvei for en mengde med ett tidsrom
+func Between(begin, end time.Time) Set {
+	return New(Interval{Begin: begin, End: end})
+}
+
+// Intervals returnerer en kopi av tidsrommene i mengden
+func (s Set) Intervals() []Interval {
+	return slices.Clone(s.intervals)
+}
+
+func (s Set) IsEmpty() bool {
+	return len(s.intervals) == 0
+}
+
+// Duration er den totale lengden av tidsrommene i mengden
+func (s Set) Duration() time.Duration {
+	var duration time.Duration
+	for _, interval := range s.intervals {
+		duration += interval.Duration()
+	}
+	return duration
+}
+
+// Minutes er den totale lengden av tidsrommene i mengden, i hele minutter. Sekunder som ikke utgjør et helt minutt
+// blir ikke telt med.
+func (s Set) Minutes() int64 {
+	return int64(s.Duration() / time.Minute)
+}
+
+// Union returnerer all tid som er i minst en av mengdene
+func (s Set) Union(other Set) Set {
+	return New(append(slices.Clone(s.intervals), other.intervals...)...)
+}
+
+// Intersect returnerer tiden som er i begge mengdene
+func (s Set) Intersect(other Set) Set {
+	var result []Interval
+	i, j := 0, 0
+	for i < len(s.intervals) && j < len(other.intervals) {
+		a, b := s.intervals[i], other.intervals[j]
+
+		overlap := Interval{Begin: later(a.Begin, b.Begin), End: earlier(a.End, b.End)}
+		if !overlap.IsEmpty() {
+			result = append(result, overlap)
+		}
+
+		if a.End.Before(b.End) {
+			i++
+		} else {
+			j++
+		}
+	}
+
+	return Set{intervals: result}
+}
+
+// Subtract returnerer tiden som er i s, men ikke i other
+func (s Set) Subtract(other Set) Set {
+	var result []Interval
+	j := 0
+	for _, interval := range s.intervals {
+		begin := interval.Begin
+		for j < len(other.intervals) && !other.intervals[j].End.After(begin) {
+			j++
+		}
+
+		for k := j; k < len(other.intervals) && other.intervals[k].Begin.Before(interval.End); k++ {
+			cut := other.intervals[k]
+			if cut.Begin.After(begin) {
+				result = append(result, Interval{Begin: begin, End: cut.Begin})
+			}
+			begin = later(begin, cut.End)
+		}
+
+		if interval.End.After(begin) {
+			result = append(result, Interval{Begin: begin, End: interval.End})
+		}
+	}
+
+	return Set{intervals: result}
+}
+
+// SplitAtMidnight deler tidsrommene ved midnatt, slik at hvert tidsrom ligger innenfor ett døgn.
+// Døgnet regnes i tidssonen til tidspunktene.
+func (s Set) SplitAtMidnight() []Interval {
+	var result []Interval
+	for _, interval := range s.intervals {
+		begin := interval.Begin
+		for begin.Before(interval.End) {
+			midnight := time.Date(begin.Year(), begin.Month(), begin.Day()+1, 0, 0, 0, 0, begin.Location())
+			end := earlier(midnight, interval.End)
+			result = append(result, Interval{Begin: begin, End: end})
+			begin = end
+		}
+	}
+	return result
+}
+
+func (s Set) String() string {
+	parts := make([]string, len(s.intervals))
+	for i, interval := range s.intervals {
+		parts[i] = interval.String()
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
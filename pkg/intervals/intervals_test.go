@@ -0,0 +1,324 @@
+package intervals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func at(day, hour, minute int) time.Time {
+	return time.Date(2022, 10, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		intervals []Interval
+		want      []Interval
+	}{
+		{
+			name: "tom mengde",
+			want: nil,
+		},
+		{
+			name: "tomme tidsrom blir fjernet",
+			intervals: []Interval{
+				{Begin: at(3, 10, 0), End: at(3, 10, 0)},
+				{Begin: at(3, 12, 0), End: at(3, 11, 0)},
+			},
+			want: nil,
+		},
+		{
+			name: "overlappende tidsrom blir slått sammen",
+			intervals: []Interval{
+				{Begin: at(3, 12, 0), End: at(3, 16, 0)},
+				{Begin: at(3, 8, 0), End: at(3, 13, 0)},
+			},
+			want: []Interval{
+				{Begin: at(3, 8, 0), End: at(3, 16, 0)},
+			},
+		},
+		{
+			name: "tidsrom som møtes blir slått sammen",
+			intervals: []Interval{
+				{Begin: at(3, 8, 0), End: at(3, 12, 0)},
+				{Begin: at(3, 12, 0), End: at(3, 16, 0)},
+			},
+			want: []Interval{
+				{Begin: at(3, 8, 0), End: at(3, 16, 0)},
+			},
+		},
+		{
+			name: "tidsrom som ligger inni et annet",
+			intervals: []Interval{
+				{Begin: at(3, 8, 0), End: at(3, 16, 0)},
+				{Begin: at(3, 10, 0), End: at(3, 11, 0)},
+			},
+			want: []Interval{
+				{Begin: at(3, 8, 0), End: at(3, 16, 0)},
+			},
+		},
+		{
+			name: "adskilte tidsrom blir sortert",
+			intervals: []Interval{
+				{Begin: at(4, 8, 0), End: at(4, 16, 0)},
+				{Begin: at(3, 20, 0), End: at(3, 23, 0)},
+			},
+			want: []Interval{
+				{Begin: at(3, 20, 0), End: at(3, 23, 0)},
+				{Begin: at(4, 8, 0), End: at(4, 16, 0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.intervals...).Intervals()
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("New() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSet_Intersect(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Set
+		b     Set
+		want  []Interval
+		total time.Duration
+	}{
+		{
+			name:  "ingen overlapp",
+			a:     Between(at(3, 8, 0), at(3, 12, 0)),
+			b:     Between(at(3, 12, 0), at(3, 16, 0)),
+			want:  nil,
+			total: 0,
+		},
+		{
+			name: "delvis overlapp",
+			a:    Between(at(3, 8, 0), at(3, 12, 0)),
+			b:    Between(at(3, 10, 0), at(3, 16, 0)),
+			want: []Interval{
+				{Begin: at(3, 10, 0), End: at(3, 12, 0)},
+			},
+			total: 2 * time.Hour,
+		},
+		{
+			name: "vakt over midnatt",
+			a:    Between(at(3, 20, 0), at(4, 8, 0)),
+			b:    Between(at(3, 0, 0), at(4, 0, 0)),
+			want: []Interval{
+				{Begin: at(3, 20, 0), End: at(4, 0, 0)},
+			},
+			total: 4 * time.Hour,
+		},
+		{
+			name: "nyttårsaften",
+			a:    Between(time.Date(2022, 12, 31, 23, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 1, 0, 0, 0, time.UTC)),
+			b:    Between(time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+			want: []Interval{
+				{Begin: time.Date(2022, 12, 31, 23, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+			total: time.Hour,
+		},
+		{
+			name: "flere tidsrom i begge mengdene",
+			a: New(
+				Interval{Begin: at(3, 0, 0), End: at(3, 7, 0)},
+				Interval{Begin: at(3, 17, 0), End: at(4, 0, 0)},
+			),
+			b: New(
+				Interval{Begin: at(3, 6, 0), End: at(3, 8, 0)},
+				Interval{Begin: at(3, 16, 0), End: at(3, 18, 0)},
+				Interval{Begin: at(3, 19, 0), End: at(3, 20, 0)},
+			),
+			want: []Interval{
+				{Begin: at(3, 6, 0), End: at(3, 7, 0)},
+				{Begin: at(3, 17, 0), End: at(3, 18, 0)},
+				{Begin: at(3, 19, 0), End: at(3, 20, 0)},
+			},
+			total: 3 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Intersect(tt.b)
+			if diff := cmp.Diff(tt.want, got.Intervals()); diff != "" {
+				t.Errorf("Intersect() mismatch (-want +got):\n%s", diff)
+			}
+			if got.Duration() != tt.total {
+				t.Errorf("Intersect().Duration() got = %v, want %v", got.Duration(), tt.total)
+			}
+		})
+	}
+}
+
+func TestSet_Subtract(t *testing.T) {
+	tests := []struct {
+		name string
+		a    Set
+		b    Set
+		want []Interval
+	}{
+		{
+			name: "trekker fra ingenting",
+			a:    Between(at(3, 8, 0), at(3, 16, 0)),
+			want: []Interval{
+				{Begin: at(3, 8, 0), End: at(3, 16, 0)},
+			},
+		},
+		{
+			name: "trekker fra hele",
+			a:    Between(at(3, 8, 0), at(3, 16, 0)),
+			b:    Between(at(3, 7, 0), at(3, 17, 0)),
+			want: nil,
+		},
+		{
+			name: "hull i midten",
+			a:    Between(at(3, 0, 0), at(4, 0, 0)),
+			b:    Between(at(3, 8, 0), at(3, 16, 0)),
+			want: []Interval{
+				{Begin: at(3, 0, 0), End: at(3, 8, 0)},
+				{Begin: at(3, 16, 0), End: at(4, 0, 0)},
+			},
+		},
+		{
+			name: "overlappende stemplinger telles kun en gang",
+			a:    Between(at(3, 0, 0), at(4, 0, 0)),
+			b: New(
+				Interval{Begin: at(3, 8, 0), End: at(3, 12, 0)},
+				Interval{Begin: at(3, 10, 0), End: at(3, 16, 0)},
+				Interval{Begin: at(3, 20, 0), End: at(3, 21, 0)},
+			),
+			want: []Interval{
+				{Begin: at(3, 0, 0), End: at(3, 8, 0)},
+				{Begin: at(3, 16, 0), End: at(3, 20, 0)},
+				{Begin: at(3, 21, 0), End: at(4, 0, 0)},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Subtract(tt.b)
+			if diff := cmp.Diff(tt.want, got.Intervals()); diff != "" {
+				t.Errorf("Subtract() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSet_Union(t *testing.T) {
+	a := New(
+		Interval{Begin: at(3, 0, 0), End: at(3, 6, 0)},
+		Interval{Begin: at(3, 20, 0), End: at(4, 0, 0)},
+	)
+	b := Between(at(3, 5, 0), at(3, 8, 0))
+
+	want := []Interval{
+		{Begin: at(3, 0, 0), End: at(3, 8, 0)},
+		{Begin: at(3, 20, 0), End: at(4, 0, 0)},
+	}
+	if diff := cmp.Diff(want, a.Union(b).Intervals()); diff != "" {
+		t.Errorf("Union() mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(a.Union(b).Intervals(), b.Union(a).Intervals()); diff != "" {
+		t.Errorf("Union() is not symmetric (-a∪b +b∪a):\n%s", diff)
+	}
+}
+
+func TestSet_SplitAtMidnight(t *testing.T) {
+	set := New(
+		Interval{Begin: time.Date(2022, 12, 30, 15, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)},
+		Interval{Begin: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+	)
+
+	want := []Interval{
+		{Begin: time.Date(2022, 12, 30, 15, 0, 0, 0, time.UTC), End: time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)},
+		{Begin: time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Begin: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 1, 8, 0, 0, 0, time.UTC)},
+		{Begin: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC), End: time.Date(2023, 1, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	if diff := cmp.Diff(want, set.SplitAtMidnight()); diff != "" {
+		t.Errorf("SplitAtMidnight() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// intervalsFromMinutes lager tidsrom fra minutter i en uke fra 28. desember 2022, slik at fuzzeren kan lage vilkårlige
+// tidsrom, også over nyttårsaften
+func intervalsFromMinutes(values ...uint16) []Interval {
+	start := time.Date(2022, 12, 28, 0, 0, 0, 0, time.UTC)
+	var intervals []Interval
+	for i := 0; i+1 < len(values); i += 2 {
+		begin := start.Add(time.Duration(int(values[i])%(7*24*60)) * time.Minute)
+		end := start.Add(time.Duration(int(values[i+1])%(7*24*60)) * time.Minute)
+		intervals = append(intervals, Interval{Begin: begin, End: end})
+	}
+	return intervals
+}
+
+func FuzzSet(f *testing.F) {
+	f.Add(uint16(0), uint16(480), uint16(240), uint16(960), uint16(1200), uint16(1440))
+	f.Add(uint16(5700), uint16(5820), uint16(0), uint16(10080), uint16(5759), uint16(5761))
+	f.Add(uint16(100), uint16(50), uint16(50), uint16(100), uint16(0), uint16(0))
+	// Feilene som er rettet i beregningen tidligere:
+	// stemplinger som overlapper trekkes kun fra vakten én gang
+	f.Add(uint16(0), uint16(1440), uint16(0), uint16(480), uint16(720), uint16(600))
+	// stemplinger på samme tidspunkt gir samme resultat uansett rekkefølge
+	f.Add(uint16(480), uint16(600), uint16(480), uint16(600), uint16(600), uint16(600))
+	// en dag som er med flere ganger gir de samme tidsrommene to ganger
+	f.Add(uint16(0), uint16(480), uint16(0), uint16(0), uint16(480), uint16(0))
+	// en stempling som slutter ved midnatt på nyttårsaften slutter kl 24:00, og ikke kl 00:00 samme dag
+	f.Add(uint16(5700), uint16(5760), uint16(1380), uint16(4320), uint16(5760), uint16(0))
+
+	f.Fuzz(func(t *testing.T, a1, a2, a3, b1, b2, b3 uint16) {
+		raw := intervalsFromMinutes(a1, a2, a3, a1+a2)
+		a := New(raw...)
+		b := New(intervalsFromMinutes(b1, b2, b3, b1+b2)...)
+
+		if diff := cmp.Diff(a.Intervals(), New(raw[1], raw[0]).Intervals()); diff != "" {
+			t.Fatalf("New() depends on the order of the intervals (-original +reversed):\n%s", diff)
+		}
+
+		// Tidsrom som overlapper telles kun én gang, men ingen av dem blir kortere
+		var longest, total time.Duration
+		for _, interval := range raw {
+			if length := interval.End.Sub(interval.Begin); length > 0 {
+				longest = max(longest, length)
+				total += length
+			}
+		}
+		if a.Duration() < longest || a.Duration() > total {
+			t.Fatalf("%v lasts %v, want between %v and %v", a, a.Duration(), longest, total)
+		}
+
+		intersection := a.Intersect(b)
+		if intersection.Duration() > a.Duration() || intersection.Duration() > b.Duration() {
+			t.Fatalf("intersection %v is longer than %v or %v", intersection, a, b)
+		}
+		if diff := cmp.Diff(intersection.Intervals(), b.Intersect(a).Intervals()); diff != "" {
+			t.Fatalf("Intersect() is not symmetric (-a∩b +b∩a):\n%s", diff)
+		}
+
+		difference := a.Subtract(b)
+		if !difference.Intersect(b).IsEmpty() {
+			t.Fatalf("difference %v overlaps %v", difference, b)
+		}
+		if diff := cmp.Diff(a.Intervals(), difference.Union(intersection).Intervals()); diff != "" {
+			t.Fatalf("(a-b)∪(a∩b) differs from a (-a +got):\n%s", diff)
+		}
+
+		union := a.Union(b)
+		if union.Duration() != a.Duration()+b.Duration()-intersection.Duration() {
+			t.Fatalf("union %v has the wrong length", union)
+		}
+
+		intervals := union.Intervals()
+		for i := 1; i < len(intervals); i++ {
+			if !intervals[i].Begin.After(intervals[i-1].End) {
+				t.Fatalf("intervals are not sorted and disjoint: %v", union)
+			}
+		}
+	})
+}
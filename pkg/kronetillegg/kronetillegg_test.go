@@ -2,12 +2,18 @@ package kronetillegg
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rounding"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
+// testRules er reglene fra særavtalen som gjaldt da testene ble skrevet
+var testRules, _ = rules.Default().At(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
 func TestCalculate(t *testing.T) {
 	type args struct {
 		minutes map[string]models.GuardDuty
@@ -97,7 +103,7 @@ func TestCalculate(t *testing.T) {
 				},
 				Skift: models.Artskode{
 					Sum:   decimal.NewFromInt(100),
-					Hours: 20,
+					Hours: decimal.NewFromInt(20),
 				},
 			},
 		},
@@ -169,14 +175,14 @@ func TestCalculate(t *testing.T) {
 				},
 				Skift: models.Artskode{
 					Sum:   decimal.NewFromInt(20),
-					Hours: 4,
+					Hours: decimal.NewFromInt(4),
 				},
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			Calculate(tt.args.minutes, tt.args.satser, tt.args.payroll)
+			Calculate(tt.args.minutes, tt.args.satser, testRules.Kronetillegg, testRules.Rounding, tt.args.payroll)
 
 			if diff := cmp.Diff(tt.want, tt.args.payroll.Artskoder); diff != "" {
 				t.Errorf("Calculate() mismatch (-want +got):\n%s", diff)
@@ -184,3 +190,30 @@ func TestCalculate(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateWithQuarterHours(t *testing.T) {
+	policies := rounding.Policies{
+		{Artskode: "skift", Per: rounding.PerDay, Method: rounding.HalfUp, Granularity: 15},
+	}
+	satser := models.Satser{
+		Helg:    decimal.NewFromInt(65),
+		Dag:     decimal.NewFromInt(15),
+		Natt:    decimal.NewFromInt(25),
+		Utvidet: decimal.NewFromInt(25),
+	}
+	minutes := map[string]models.GuardDuty{
+		"2022-10-12": {Skifttillegg: 255},
+	}
+
+	// Timene som rapporteres er de samme som summen er utbetalt for, også når de ikke er hele
+	payroll := &models.Payroll{}
+	Calculate(minutes, satser, testRules.Kronetillegg, policies, payroll)
+
+	want := models.Artskode{
+		Sum:   decimal.RequireFromString("21.25"),
+		Hours: decimal.RequireFromString("4.25"),
+	}
+	if diff := cmp.Diff(want, payroll.Artskoder.Skift); diff != "" {
+		t.Errorf("Calculate() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -11,6 +11,10 @@ type Clocking struct {
 	Out time.Time
 	// OvertimeBecauseOfGuardDuty - Man har hatt vakt med utrykning
 	OtG bool
+	// Overtime er satt for all overtid, også overtid som ikke er merket som beredskapsvakt
+	Overtime bool
+	// GuardDutyMarking er koden eller teksten fra MinWinTid som gjorde at overtiden ble regnet som utrykning
+	GuardDutyMarking string
 }
 
 type TimeSheet struct {
@@ -23,13 +27,18 @@ type TimeSheet struct {
 	Clockings     []Clocking
 }
 
+// WorkingMinutes er arbeidstiden etter skjema i hele minutter. MinWinTid oppgir arbeidstiden i timer med desimaler,
+// så den regnes om uten å gå via flyttall.
+func (t TimeSheet) WorkingMinutes() int64 {
+	return decimal.NewFromFloat(t.WorkingHours).Mul(decimal.NewFromInt(60)).Round(0).IntPart()
+}
+
 type MinWinTid struct {
 	Ident        string
 	ResourceID   string
 	ApproverID   string
 	ApproverName string
 	Timesheet    map[string]TimeSheet
-	Satser       Satser
 }
 
 type MWTStempling struct {
@@ -38,11 +47,13 @@ type MWTStempling struct {
 	Type               string `json:"type"`
 	Fravarkode         int    `json:"fravar_kode"`
 	OvertidBegrunnelse string `json:"overtid_begrunnelse"`
+	OvertidKode        string `json:"overtid_kode"`
 }
 
 type MWTStilling struct {
-	RATEK001      int    `json:"rate_k001"`
-	Stillingskode string `json:"post_id"`
+	RATEK001      int     `json:"rate_k001"`
+	Stillingskode string  `json:"post_id"`
+	ParttimePct   float64 `json:"parttime_pct"`
 }
 
 type MWTDag struct {
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,13 +18,42 @@ type Vaktplan struct {
 	Schedule map[string][]Period `json:"schedule"`
 }
 
+// Beredskapsvakt er en vaktperiode fra Vaktor Plan som venter på beregning, slik den er etter at raden i databasen
+// er dekryptert
+type Beredskapsvakt struct {
+	ID          uuid.UUID
+	Ident       string
+	Plan        json.RawMessage
+	PeriodBegin time.Time
+	PeriodEnd   time.Time
+}
+
 // GuardDuty keeps track of minutes not worked in a given guard duty
 type GuardDuty struct {
-	Hvilende2000  float64
-	Hvilende0006  float64
-	Hvilende0620  float64
-	Helligdag0620 float64
-	Helgetillegg  float64
-	Skifttillegg  float64
+	Hvilende2000  int64
+	Hvilende0006  int64
+	Hvilende0620  int64
+	Helligdag0620 int64
+	Helgetillegg  int64
+	Skifttillegg  int64
 	IsWeekend     bool
 }
+
+// Bucket returnerer minuttene for en gitt type vakt, slik den er oppgitt i reglene
+func (g *GuardDuty) Bucket(name string) (*int64, bool) {
+	switch name {
+	case "hvilende2000":
+		return &g.Hvilende2000, true
+	case "hvilende0006":
+		return &g.Hvilende0006, true
+	case "hvilende0620":
+		return &g.Hvilende0620, true
+	case "helligdag0620":
+		return &g.Helligdag0620, true
+	case "helgetillegg":
+		return &g.Helgetillegg, true
+	case "skifttillegg":
+		return &g.Skifttillegg, true
+	}
+	return nil, false
+}
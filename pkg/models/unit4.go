@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
@@ -12,9 +15,41 @@ type Satser struct {
 	Utvidet decimal.Decimal `json:"skift"`
 }
 
+// Sats returnerer satsen med et gitt navn, slik den er oppgitt i reglene
+func (s Satser) Sats(name string) (decimal.Decimal, bool) {
+	switch name {
+	case "dag":
+		return s.Dag, true
+	case "natt":
+		return s.Natt, true
+	case "helg":
+		return s.Helg, true
+	case "utvidet":
+		return s.Utvidet, true
+	}
+	return decimal.Decimal{}, false
+}
+
+// Equal sier om alle satsene er like
+func (s Satser) Equal(other Satser) bool {
+	return s.Dag.Equal(other.Dag) && s.Natt.Equal(other.Natt) && s.Helg.Equal(other.Helg) && s.Utvidet.Equal(other.Utvidet)
+}
+
 type Artskode struct {
-	Sum   decimal.Decimal `json:"sum"`
-	Hours int64           `json:"hours"`
+	Sum decimal.Decimal `json:"sum"`
+	// Hours er timene summen er utbetalt for. Avrundes det til deler av en time, er timene det også.
+	Hours decimal.Decimal `json:"hours"`
+}
+
+// MarshalJSON skriver timene som et tall, slik Vaktor Plan tar imot dem
+func (a Artskode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Sum   decimal.Decimal `json:"sum"`
+		Hours json.Number     `json:"hours"`
+	}{
+		Sum:   a.Sum,
+		Hours: json.Number(a.Hours.String()),
+	})
 }
 
 type Artskoder struct {
@@ -26,11 +61,183 @@ type Artskoder struct {
 	Utrykning Artskode `json:"2685"`
 }
 
+// Artskode returnerer artskoden med et gitt navn, slik den er oppgitt i reglene
+func (a *Artskoder) Artskode(name string) (*Artskode, bool) {
+	switch name {
+	case "morgen":
+		return &a.Morgen, true
+	case "kveld":
+		return &a.Kveld, true
+	case "dag":
+		return &a.Dag, true
+	case "helg":
+		return &a.Helg, true
+	case "skift":
+		return &a.Skift, true
+	case "utrykning":
+		return &a.Utrykning, true
+	}
+	return nil, false
+}
+
+// Add legger sammen artskodene i to utbetalinger
+func (a Artskoder) Add(other Artskoder) Artskoder {
+	add := func(x, y Artskode) Artskode {
+		return Artskode{Sum: x.Sum.Add(y.Sum), Hours: x.Hours.Add(y.Hours)}
+	}
+
+	return Artskoder{
+		Morgen:    add(a.Morgen, other.Morgen),
+		Kveld:     add(a.Kveld, other.Kveld),
+		Dag:       add(a.Dag, other.Dag),
+		Helg:      add(a.Helg, other.Helg),
+		Skift:     add(a.Skift, other.Skift),
+		Utrykning: add(a.Utrykning, other.Utrykning),
+	}
+}
+
+// NumberedArtskode er en artskode med nummeret den føres på
+type NumberedArtskode struct {
+	Number string
+	Artskode
+}
+
+// Numbered returnerer artskodene i rekkefølge, med nummeret de føres på
+func (a Artskoder) Numbered() []NumberedArtskode {
+	return []NumberedArtskode{
+		{"2680", a.Morgen},
+		{"2681", a.Kveld},
+		{"2682", a.Dag},
+		{"2683", a.Helg},
+		{"2684", a.Skift},
+		{"2685", a.Utrykning},
+	}
+}
+
+// artskodeNumbers er nummeret til hver artskode, slik de er navngitt i reglene
+var artskodeNumbers = map[string]string{
+	"morgen":    "2680",
+	"kveld":     "2681",
+	"dag":       "2682",
+	"helg":      "2683",
+	"skift":     "2684",
+	"utrykning": "2685",
+}
+
+// Delene av beregningen som runder av minutter til hele timer. Samme minutter kan rundes av i flere av dem, for
+// eksempel både for kronetillegget og overtidstillegget på dagtid, så avrundingen rapporteres for hver del for seg.
+const (
+	ResidualKronetillegg = "kronetillegg"
+	ResidualOvertime     = "overtid"
+	ResidualCallout      = "utrykning"
+)
+
+// Residuals er minuttene som gikk tapt (positivt) eller ble lagt til (negativt) i avrundingen, per del av
+// beregningen og per artskode
+type Residuals map[string]map[string]int64
+
+// Add legger til minutter fra avrundingen av en artskode i en del av beregningen, med artskoden oppgitt med
+// navnet fra reglene
+func (r *Residuals) Add(component, artskode string, minutes int64) {
+	if minutes == 0 {
+		return
+	}
+	r.add(component, artskodeNumbers[artskode], minutes)
+}
+
+func (r *Residuals) add(component, number string, minutes int64) {
+	if *r == nil {
+		*r = make(Residuals)
+	}
+	if (*r)[component] == nil {
+		(*r)[component] = make(map[string]int64)
+	}
+	(*r)[component][number] += minutes
+}
+
+// Merge legger til minuttene fra en annen avrunding
+func (r *Residuals) Merge(other Residuals) {
+	for component, numbers := range other {
+		for number, minutes := range numbers {
+			r.add(component, number, minutes)
+		}
+	}
+}
+
+// CalloutPart er minuttene av en utrykning som ble utbetalt med en gitt artskode og sats
+type CalloutPart struct {
+	Artskode string `json:"artskode"`
+	Sats     string `json:"sats"`
+	Minutes  int64  `json:"minutes"`
+}
+
+// Callout er en utrykning under vakt, slik den ble utbetalt. Minuttene er før avrundingen til hele timer.
+type Callout struct {
+	Begin time.Time `json:"start_timestamp"`
+	End   time.Time `json:"end_timestamp"`
+	// Minutes er minuttene med overtid under vakten
+	Minutes int64 `json:"minutes"`
+	// MinimumMinutes er minuttene som ble lagt til fordi utrykningen var kortere enn minste utrykning
+	MinimumMinutes int64 `json:"minimum_minutes,omitempty"`
+	// UnpaidMinutes er minuttene som ikke er i et tidsrom som gir utrykningstillegg
+	UnpaidMinutes int64         `json:"unpaid_minutes,omitempty"`
+	Parts         []CalloutPart `json:"parts,omitempty"`
+}
+
+// Add legger til minutter som ble utbetalt med en artskode og sats, oppgitt med navnene fra reglene
+func (c *Callout) Add(artskode, sats string, minutes int64) {
+	if minutes == 0 {
+		return
+	}
+	for i := range c.Parts {
+		if c.Parts[i].Artskode == artskodeNumbers[artskode] && c.Parts[i].Sats == sats {
+			c.Parts[i].Minutes += minutes
+			return
+		}
+	}
+	c.Parts = append(c.Parts, CalloutPart{Artskode: artskodeNumbers[artskode], Sats: sats, Minutes: minutes})
+}
+
+// PricedDay viser hva en dag med vakt ble priset etter
+type PricedDay struct {
+	Date          string          `json:"date"`
+	Salary        decimal.Decimal `json:"salary"`
+	Stillingskode string          `json:"stillingskode"`
+	// AnnualHours er årstimene timelønnen for overtid er regnet ut fra
+	AnnualHours int64  `json:"annual_hours"`
+	Satser      Satser `json:"satser"`
+	// RulesVersion er versjonen av reglene fra særavtalen dagen er beregnet etter
+	RulesVersion string `json:"rules_version"`
+}
+
+// PayrollLine er en del av utbetalingen som skal føres på en egen stillingskode, eller i en egen måned
+type PayrollLine struct {
+	Stillingskode string `json:"stillingskode"`
+	// Month er måneden linjen skal føres i, som "2006-01". Kun satt når utbetalingen deles per måned.
+	Month     string    `json:"month,omitempty"`
+	Artskoder Artskoder `json:"artskoder"`
+	Residuals Residuals `json:"rounding_residual_minutes,omitempty"`
+}
+
 type Payroll struct {
-	ID            uuid.UUID
-	ApproverID    string    `json:"approver_id"`
-	ApproverName  string    `json:"approver_name"`
-	Artskoder     Artskoder `json:"artskoder"`
-	CommitSHA     string    `json:"commit_sha"`
-	Stillingskode string    `json:"stillingskode"`
+	ID           uuid.UUID
+	ApproverID   string    `json:"approver_id"`
+	ApproverName string    `json:"approver_name"`
+	Artskoder    Artskoder `json:"artskoder"`
+	CommitSHA    string    `json:"commit_sha"`
+	// RulesVersion er versjonene av reglene fra særavtalen som er brukt i beregningen, skilt med komma når
+	// vaktperioden går over i en ny versjon
+	RulesVersion  string `json:"rules_version"`
+	Stillingskode string `json:"stillingskode"`
+	// Lines er kun satt når stillingskoden har endret seg i løpet av vaktperioden, eller perioden går over
+	// et månedsskifte og utbetalingen deles per måned
+	Lines []PayrollLine `json:"lines,omitempty"`
+	// Residuals viser hvor mye avrundingen til hele timer har påvirket hver artskode i hver del av beregningen
+	Residuals Residuals `json:"rounding_residual_minutes,omitempty"`
+	// Callouts er utrykningene i perioden, og hvordan hver av dem ble utbetalt
+	Callouts []Callout `json:"callouts,omitempty"`
+	// Days er hver dag med vakt, og lønnen, stillingskoden og satsene dagen ble priset etter
+	Days []PricedDay `json:"days,omitempty"`
+	// Warnings er ting i timelisten vakthaver bør se over, selv om utbetalingen kunne beregnes
+	Warnings []string `json:"warnings,omitempty"`
 }
@@ -2,55 +2,65 @@ package overtime
 
 import (
 	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rounding"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
-func Calculate(minutes map[string]models.GuardDuty, salary decimal.Decimal, payroll *models.Payroll) {
-	overtimeWeekendMinutes := 0.0
-	overtimeHolidayMinutes := 0.0
-	overtimeDayMinutes := 0.0
-	overtimeEveningMinutes := 0.0
-	overtimeMorningMinutes := 0.0
+// Calculate legger til overtidstillegg for minuttene med vakt. Timelønnen er årslønnen delt på årstimene i avtalen
+// den ansatte har, og faktorene er bestemt av reglene. Timene blir avrundet for hver type overtid for seg, etter
+// avrundingen for artskoden.
+func Calculate(minutes map[string]models.GuardDuty, salary decimal.Decimal, annualHours int64, parameters rules.Overtime, policies rounding.Policies, payroll *models.Payroll) {
+	var overtimeWeekendMinutes []int64
+	var overtimeHolidayMinutes []int64
+	var overtimeDayMinutes []int64
+	var overtimeEveningMinutes []int64
+	var overtimeMorningMinutes []int64
 
 	for _, duty := range minutes {
 		if duty.IsWeekend {
-			overtimeWeekendMinutes += duty.Helgetillegg
+			overtimeWeekendMinutes = append(overtimeWeekendMinutes, duty.Helgetillegg)
 		} else {
-			overtimeDayMinutes += duty.Hvilende0620
-			overtimeHolidayMinutes += duty.Helligdag0620
-			overtimeEveningMinutes += duty.Hvilende2000
-			overtimeMorningMinutes += duty.Hvilende0006
+			overtimeDayMinutes = append(overtimeDayMinutes, duty.Hvilende0620)
+			overtimeHolidayMinutes = append(overtimeHolidayMinutes, duty.Helligdag0620)
+			overtimeEveningMinutes = append(overtimeEveningMinutes, duty.Hvilende2000)
+			overtimeMorningMinutes = append(overtimeMorningMinutes, duty.Hvilende0006)
 		}
 	}
 
-	ots50 := salary.Div(decimal.NewFromInt(1850)).Mul(decimal.NewFromFloat(1.5))
-	ots100 := salary.Div(decimal.NewFromInt(1850)).Mul(decimal.NewFromInt(2))
+	hourlySalary := salary.Div(decimal.NewFromInt(annualHours))
+	ots50 := hourlySalary.Mul(parameters.Multiplier50)
+	ots100 := hourlySalary.Mul(parameters.Multiplier100)
 
-	minutesInHour := decimal.NewFromInt(60)
-	fifthOfAnHour := decimal.NewFromInt(5)
+	fifthOfAnHour := decimal.NewFromInt(parameters.Divisor)
 
-	overtimeDayHours := decimal.NewFromFloat(overtimeDayMinutes).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Dag.Hours += overtimeDayHours.IntPart()
+	overtimeDayHours, residual := policies.For("dag").Hours(overtimeDayMinutes)
+	payroll.Residuals.Add(models.ResidualOvertime, "dag", residual)
+	payroll.Artskoder.Dag.Hours = payroll.Artskoder.Dag.Hours.Add(overtimeDayHours)
 	overtimeDay := overtimeDayHours.Mul(ots50).Div(fifthOfAnHour).Round(2)
 	payroll.Artskoder.Dag.Sum = payroll.Artskoder.Dag.Sum.Add(overtimeDay)
 
-	overtimeMorningHours := decimal.NewFromFloat(overtimeMorningMinutes).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Morgen.Hours += overtimeMorningHours.IntPart()
+	overtimeMorningHours, residual := policies.For("morgen").Hours(overtimeMorningMinutes)
+	payroll.Residuals.Add(models.ResidualOvertime, "morgen", residual)
+	payroll.Artskoder.Morgen.Hours = payroll.Artskoder.Morgen.Hours.Add(overtimeMorningHours)
 	overtimeMorning := overtimeMorningHours.Mul(ots100).Div(fifthOfAnHour).Round(2)
 	payroll.Artskoder.Morgen.Sum = payroll.Artskoder.Morgen.Sum.Add(overtimeMorning)
 
-	overtimeEveningHours := decimal.NewFromFloat(overtimeEveningMinutes).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Kveld.Hours += overtimeEveningHours.IntPart()
+	overtimeEveningHours, residual := policies.For("kveld").Hours(overtimeEveningMinutes)
+	payroll.Residuals.Add(models.ResidualOvertime, "kveld", residual)
+	payroll.Artskoder.Kveld.Hours = payroll.Artskoder.Kveld.Hours.Add(overtimeEveningHours)
 	overtimeEvening := overtimeEveningHours.Mul(ots100).Div(fifthOfAnHour).Round(2)
 	payroll.Artskoder.Kveld.Sum = payroll.Artskoder.Kveld.Sum.Add(overtimeEvening)
 
-	overtimeWeekendHours := decimal.NewFromFloat(overtimeWeekendMinutes).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Helg.Hours += overtimeWeekendHours.IntPart()
+	overtimeWeekendHours, residual := policies.For("helg").Hours(overtimeWeekendMinutes)
+	payroll.Residuals.Add(models.ResidualOvertime, "helg", residual)
+	payroll.Artskoder.Helg.Hours = payroll.Artskoder.Helg.Hours.Add(overtimeWeekendHours)
 	overtimeWeekend := overtimeWeekendHours.Mul(ots100).Div(fifthOfAnHour).Round(2)
 	payroll.Artskoder.Helg.Sum = payroll.Artskoder.Helg.Sum.Add(overtimeWeekend)
 
-	overtimeHolidayHours := decimal.NewFromFloat(overtimeHolidayMinutes).DivRound(minutesInHour, 0)
-	payroll.Artskoder.Dag.Hours += overtimeHolidayHours.IntPart()
+	overtimeHolidayHours, residual := policies.For("dag").Hours(overtimeHolidayMinutes)
+	payroll.Residuals.Add(models.ResidualOvertime, "dag", residual)
+	payroll.Artskoder.Dag.Hours = payroll.Artskoder.Dag.Hours.Add(overtimeHolidayHours)
 	overtimeHoliday := overtimeHolidayHours.Mul(ots100).Div(fifthOfAnHour).Round(2)
 	payroll.Artskoder.Dag.Sum = payroll.Artskoder.Dag.Sum.Add(overtimeHoliday)
 }
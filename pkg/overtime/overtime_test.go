@@ -2,17 +2,23 @@ package overtime
 
 import (
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	"github.com/shopspring/decimal"
 )
 
+// testRules er reglene fra særavtalen slik de gjaldt i 2022
+var testRules, _ = rules.Default().At(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
 func TestCalculate(t *testing.T) {
 	type args struct {
-		minutes map[string]models.GuardDuty
-		salary  decimal.Decimal
-		payroll *models.Payroll
+		minutes     map[string]models.GuardDuty
+		salary      decimal.Decimal
+		annualHours int64
+		payroll     *models.Payroll
 	}
 	tests := []struct {
 		name    string
@@ -23,8 +29,9 @@ func TestCalculate(t *testing.T) {
 		{
 			name: "Beredskapsvakt en uke",
 			args: args{
-				payroll: &models.Payroll{},
-				salary:  decimal.NewFromInt(750_000),
+				payroll:     &models.Payroll{},
+				salary:      decimal.NewFromInt(750_000),
+				annualHours: 1850,
 				minutes: map[string]models.GuardDuty{
 					"2022-10-12": {
 						Hvilende2000: 240,
@@ -81,19 +88,19 @@ func TestCalculate(t *testing.T) {
 			want: models.Artskoder{
 				Morgen: models.Artskode{
 					Sum:   decimal.NewFromFloat(4_864.86),
-					Hours: 30,
+					Hours: decimal.NewFromInt(30),
 				},
 				Dag: models.Artskode{
 					Sum:   decimal.NewFromFloat(3_770.27),
-					Hours: 31,
+					Hours: decimal.NewFromInt(31),
 				},
 				Kveld: models.Artskode{
 					Sum:   decimal.NewFromFloat(3_243.24),
-					Hours: 20,
+					Hours: decimal.NewFromInt(20),
 				},
 				Helg: models.Artskode{
 					Sum:   decimal.NewFromFloat(7_783.78),
-					Hours: 48,
+					Hours: decimal.NewFromInt(48),
 				},
 			},
 			wantErr: false,
@@ -102,8 +109,9 @@ func TestCalculate(t *testing.T) {
 		{
 			name: "Utrykning i helg (søndag)",
 			args: args{
-				payroll: &models.Payroll{},
-				salary:  decimal.NewFromInt(750_000),
+				payroll:     &models.Payroll{},
+				salary:      decimal.NewFromInt(750_000),
+				annualHours: 1850,
 				minutes: map[string]models.GuardDuty{
 					"2022-10-15": {
 						Hvilende2000: 240,
@@ -126,7 +134,40 @@ func TestCalculate(t *testing.T) {
 			want: models.Artskoder{
 				Helg: models.Artskode{
 					Sum:   decimal.NewFromFloat(7_459.46),
-					Hours: 46,
+					Hours: decimal.NewFromInt(46),
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Utrykning i helg med avtale om færre årstimer",
+			args: args{
+				payroll:     &models.Payroll{},
+				salary:      decimal.NewFromInt(750_000),
+				annualHours: 1750,
+				minutes: map[string]models.GuardDuty{
+					"2022-10-15": {
+						Hvilende2000: 240,
+						Hvilende0006: 360,
+						Hvilende0620: 840,
+						Helgetillegg: 1440,
+						Skifttillegg: 0,
+						IsWeekend:    true,
+					},
+					"2022-10-16": {
+						Hvilende2000: 120,
+						Hvilende0006: 360,
+						Hvilende0620: 840,
+						Helgetillegg: 1320,
+						Skifttillegg: 0,
+						IsWeekend:    true,
+					},
+				},
+			},
+			want: models.Artskoder{
+				Helg: models.Artskode{
+					Sum:   decimal.NewFromFloat(7_885.71),
+					Hours: decimal.NewFromInt(46),
 				},
 			},
 			wantErr: false,
@@ -134,7 +175,7 @@ func TestCalculate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			Calculate(tt.args.minutes, tt.args.salary, tt.args.payroll)
+			Calculate(tt.args.minutes, tt.args.salary, tt.args.annualHours, testRules.Overtime, nil, tt.args.payroll)
 			if diff := cmp.Diff(tt.want, tt.args.payroll.Artskoder); diff != "" {
 				t.Errorf("Calculate() mismatch (-want +got):\n%s", diff)
 			}
@@ -0,0 +1,78 @@
+package recalculation
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/navikt/vaktor-lonn/pkg/calculator"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
+)
+
+// expectedPayrollFile er utbetalingen en vaktperiode i testdata/golden skal gi
+const expectedPayrollFile = "expected_payroll.json"
+
+var update = flag.Bool("update", false, "skriv expected_payroll.json på nytt med utbetalingen som beregnes nå")
+
+// TestGolden beregner hver vaktperiode i testdata/golden, og sammenligner med expected_payroll.json. Nye vaktperioder
+// legges til som en mappe med vaktplan.json og minwintid.json, og forventet utbetaling lages med
+//
+//	go test ./pkg/recalculation -run TestGolden -update
+func TestGolden(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "golden", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) == 0 {
+		t.Fatal("found no cases in testdata/golden")
+	}
+
+	current := Calculator{Rules: rules.Default(), Options: calculator.Options{}}
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			archive, err := LoadCase(dir)
+			if err != nil {
+				t.Fatalf("LoadCase() returned an error: %v", err)
+			}
+
+			got, err := current.Calculate(archive)
+			if err != nil {
+				t.Fatalf("Calculate() returned an error: %v", err)
+			}
+
+			// Commit-SHA-en er fra bygget, og er ikke en del av beregningen
+			got.CommitSHA = ""
+
+			path := filepath.Join(dir, expectedPayrollFile)
+			if *update {
+				body, err := json.MarshalIndent(got, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, append(body, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			body, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading %v, run with -update to create it: %v", path, err)
+			}
+
+			var want models.Payroll
+			if err := json.Unmarshal(body, &want); err != nil {
+				t.Fatalf("unmarshaling %v: %v", path, err)
+			}
+
+			if diff := cmp.Diff(want, got, cmpopts.IgnoreFields(models.Payroll{}, "CommitSHA")); diff != "" {
+				t.Errorf("Calculate() mismatch (-want +got), run with -update if the change is intended:\n%s", diff)
+			}
+		})
+	}
+}
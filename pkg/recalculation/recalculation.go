@@ -0,0 +1,268 @@
+package recalculation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/navikt/vaktor-lonn/pkg/calculator"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
+	"github.com/navikt/vaktor-lonn/pkg/service"
+	"github.com/shopspring/decimal"
+)
+
+// Filene en arkivert vaktperiode består av. Utbetalingen er valgfri, og er den som ble sendt til Vaktor Plan.
+const (
+	PlanFile      = "vaktplan.json"
+	MinWinTidFile = "minwintid.json"
+	PayrollFile   = "payroll.json"
+)
+
+// Case er en arkivert vaktperiode: vaktplanen, responsen fra MinWinTid, og utbetalingen hvis den er arkivert
+type Case struct {
+	Name           string
+	Beredskapsvakt models.Beredskapsvakt
+	MinWinTid      models.MWTRespons
+	Archived       *models.Payroll
+}
+
+// ParsePlan leser vaktplanen slik den ligger i databasen. Perioden starter ved start_timestamp, eller ved den første
+// vakten i planen hvis den mangler.
+func ParsePlan(plan []byte) (models.Beredskapsvakt, error) {
+	var period struct {
+		models.Vaktplan
+		Begin time.Time `json:"start_timestamp"`
+		End   time.Time `json:"end_timestamp"`
+	}
+	if err := json.Unmarshal(plan, &period); err != nil {
+		return models.Beredskapsvakt{}, fmt.Errorf("unmarshaling plan: %w", err)
+	}
+
+	if period.Begin.IsZero() {
+		for _, periods := range period.Schedule {
+			for _, p := range periods {
+				if period.Begin.IsZero() || p.Begin.Before(period.Begin) {
+					period.Begin = p.Begin
+				}
+				if p.End.After(period.End) {
+					period.End = p.End
+				}
+			}
+		}
+	}
+
+	return models.Beredskapsvakt{
+		ID:          period.ID,
+		Ident:       period.Ident,
+		Plan:        plan,
+		PeriodBegin: period.Begin,
+		PeriodEnd:   period.End,
+	}, nil
+}
+
+// LoadCase leser en arkivert vaktperiode fra en mappe
+func LoadCase(dir string) (Case, error) {
+	plan, err := os.ReadFile(filepath.Join(dir, PlanFile))
+	if err != nil {
+		return Case{}, err
+	}
+
+	beredskapsvakt, err := ParsePlan(plan)
+	if err != nil {
+		return Case{}, fmt.Errorf("%v: %w", dir, err)
+	}
+
+	file, err := os.Open(filepath.Join(dir, MinWinTidFile))
+	if err != nil {
+		return Case{}, err
+	}
+	defer file.Close()
+
+	response, err := service.DecodeMinWinTid(file)
+	if err != nil {
+		return Case{}, fmt.Errorf("%v: %w", dir, err)
+	}
+
+	c := Case{
+		Name:           filepath.Base(dir),
+		Beredskapsvakt: beredskapsvakt,
+		MinWinTid:      response,
+	}
+
+	archived, err := os.ReadFile(filepath.Join(dir, PayrollFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Case{}, err
+	}
+	if err == nil {
+		c.Archived = &models.Payroll{}
+		if err := json.Unmarshal(archived, c.Archived); err != nil {
+			return Case{}, fmt.Errorf("%v: unmarshaling payroll: %w", dir, err)
+		}
+	}
+
+	return c, nil
+}
+
+// LoadDir leser alle arkiverte vaktperioder i en mappe, der hver vaktperiode ligger i en egen undermappe
+func LoadDir(dir string) ([]Case, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var cases []Case
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		c, err := LoadCase(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		cases = append(cases, c)
+	}
+
+	return cases, nil
+}
+
+// Calculator er en oppsatt beregning: reglene og valgene utbetalingen beregnes med
+type Calculator struct {
+	Rules   rules.Versions
+	Options calculator.Options
+	// Archived bruker utbetalingen som er arkivert i stedet for å beregne den på nytt
+	Archived bool
+}
+
+// Calculate beregner utbetalingen for en arkivert vaktperiode
+func (c Calculator) Calculate(archive Case) (models.Payroll, error) {
+	if c.Archived {
+		if archive.Archived == nil {
+			return models.Payroll{}, fmt.Errorf("there is no archived payroll")
+		}
+		return *archive.Archived, nil
+	}
+
+	payroll, message, err := service.CalculateSalary(archive.Beredskapsvakt, archive.MinWinTid, c.Rules, c.Options)
+	if err != nil {
+		return models.Payroll{}, fmt.Errorf("%v: %w", message, err)
+	}
+	if payroll == nil {
+		return models.Payroll{}, errors.New(message)
+	}
+
+	return *payroll, nil
+}
+
+// ArtskodeDiff er forskjellen på en artskode mellom to beregninger
+type ArtskodeDiff struct {
+	Artskode      string          `json:"artskode"`
+	BaselineHours decimal.Decimal `json:"baseline_hours"`
+	Hours         decimal.Decimal `json:"hours"`
+	BaselineSum   decimal.Decimal `json:"baseline_sum"`
+	Sum           decimal.Decimal `json:"sum"`
+}
+
+// HoursDiff er endringen i timer
+func (d ArtskodeDiff) HoursDiff() decimal.Decimal {
+	return d.Hours.Sub(d.BaselineHours)
+}
+
+// SumDiff er endringen i kroner
+func (d ArtskodeDiff) SumDiff() decimal.Decimal {
+	return d.Sum.Sub(d.BaselineSum)
+}
+
+// Compare returnerer artskodene som er forskjellige i to utbetalinger
+func Compare(baseline, current models.Artskoder) []ArtskodeDiff {
+	var diffs []ArtskodeDiff
+	currentArtskoder := current.Numbered()
+	for i, before := range baseline.Numbered() {
+		after := currentArtskoder[i]
+		if before.Hours.Equal(after.Hours) && before.Sum.Equal(after.Sum) {
+			continue
+		}
+
+		diffs = append(diffs, ArtskodeDiff{
+			Artskode:      before.Number,
+			BaselineHours: before.Hours,
+			Hours:         after.Hours,
+			BaselineSum:   before.Sum,
+			Sum:           after.Sum,
+		})
+	}
+
+	return diffs
+}
+
+// PlanDiff er forskjellen på en arkivert vaktperiode mellom to beregninger
+type PlanDiff struct {
+	Name      string         `json:"name"`
+	ID        string         `json:"id"`
+	Artskoder []ArtskodeDiff `json:"artskoder,omitempty"`
+	// BaselineError og Error er satt når beregningen feilet
+	BaselineError string `json:"baseline_error,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// Changed sier om vaktperioden blir beregnet forskjellig, eller om bare en av beregningene feilet
+func (d PlanDiff) Changed() bool {
+	return len(d.Artskoder) > 0 || d.BaselineError != d.Error
+}
+
+// Summary er forskjellene for alle de arkiverte vaktperiodene
+type Summary struct {
+	Plans     int            `json:"plans"`
+	Changed   int            `json:"changed"`
+	Failed    int            `json:"failed"`
+	Artskoder []ArtskodeDiff `json:"artskoder,omitempty"`
+	Diffs     []PlanDiff     `json:"diffs,omitempty"`
+}
+
+// Diff beregner alle de arkiverte vaktperiodene med begge beregningene, og oppsummerer forskjellene. Vaktperioder
+// som ble beregnet likt er kun med i antallet.
+func Diff(cases []Case, baseline, current Calculator) Summary {
+	var summary Summary
+	var totalBaseline, totalCurrent models.Artskoder
+	for _, archive := range cases {
+		diff := PlanDiff{
+			Name: archive.Name,
+			ID:   archive.Beredskapsvakt.ID.String(),
+		}
+
+		before, err := baseline.Calculate(archive)
+		if err != nil {
+			diff.BaselineError = err.Error()
+		}
+		after, err := current.Calculate(archive)
+		if err != nil {
+			diff.Error = err.Error()
+		}
+
+		summary.Plans++
+		if diff.BaselineError != "" || diff.Error != "" {
+			summary.Failed++
+		} else {
+			diff.Artskoder = Compare(before.Artskoder, after.Artskoder)
+			totalBaseline = totalBaseline.Add(before.Artskoder)
+			totalCurrent = totalCurrent.Add(after.Artskoder)
+		}
+
+		if diff.Changed() {
+			summary.Changed++
+			summary.Diffs = append(summary.Diffs, diff)
+		}
+	}
+
+	summary.Artskoder = Compare(totalBaseline, totalCurrent)
+	sort.SliceStable(summary.Diffs, func(i, j int) bool {
+		return summary.Diffs[i].Name < summary.Diffs[j].Name
+	})
+
+	return summary
+}
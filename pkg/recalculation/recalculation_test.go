@@ -0,0 +1,132 @@
+package recalculation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
+	"github.com/shopspring/decimal"
+)
+
+func TestParsePlan(t *testing.T) {
+	tests := []struct {
+		name      string
+		plan      string
+		wantBegin time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "periode fra vaktplanen",
+			plan:      `{"id":"b4ac8e53-9d64-4557-8ef8-d00774ab9c06","start_timestamp":"2022-10-05T12:00:00Z","end_timestamp":"2022-10-06T12:00:00Z","schedule":{"2022-10-05":[{"start_timestamp":"2022-10-05T16:00:00Z","end_timestamp":"2022-10-06T00:00:00Z"}]}}`,
+			wantBegin: time.Date(2022, 10, 5, 12, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2022, 10, 6, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "periode fra vaktene",
+			plan:      `{"id":"b4ac8e53-9d64-4557-8ef8-d00774ab9c06","schedule":{"2022-10-06":[{"start_timestamp":"2022-10-06T00:00:00Z","end_timestamp":"2022-10-06T08:00:00Z"}],"2022-10-05":[{"start_timestamp":"2022-10-05T16:00:00Z","end_timestamp":"2022-10-06T00:00:00Z"}]}}`,
+			wantBegin: time.Date(2022, 10, 5, 16, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2022, 10, 6, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:    "ugyldig vaktplan",
+			plan:    `{"schedule":[]}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePlan([]byte(tt.plan))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlan() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if !got.PeriodBegin.Equal(tt.wantBegin) || !got.PeriodEnd.Equal(tt.wantEnd) {
+				t.Errorf("ParsePlan() period = %v-%v, want %v-%v", got.PeriodBegin, got.PeriodEnd, tt.wantBegin, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := models.Artskoder{
+		Dag:  models.Artskode{Sum: decimal.NewFromInt(150), Hours: decimal.NewFromInt(10)},
+		Helg: models.Artskode{Sum: decimal.NewFromInt(650), Hours: decimal.NewFromInt(10)},
+	}
+	current := models.Artskoder{
+		Dag:  models.Artskode{Sum: decimal.RequireFromString("150.00"), Hours: decimal.NewFromInt(10)},
+		Helg: models.Artskode{Sum: decimal.NewFromInt(715), Hours: decimal.NewFromInt(11)},
+	}
+
+	want := []ArtskodeDiff{
+		{
+			Artskode:      "2683",
+			BaselineHours: decimal.NewFromInt(10),
+			Hours:         decimal.NewFromInt(11),
+			BaselineSum:   decimal.NewFromInt(650),
+			Sum:           decimal.NewFromInt(715),
+		},
+	}
+	got := Compare(baseline, current)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("Compare() mismatch (-want +got):\n%s", diff)
+	}
+	if !got[0].HoursDiff().Equal(decimal.NewFromInt(1)) || !got[0].SumDiff().Equal(decimal.NewFromInt(65)) {
+		t.Errorf("Compare() diff = %v timer og %v kr, want 1 timer og 65 kr", got[0].HoursDiff(), got[0].SumDiff())
+	}
+}
+
+func TestDiff(t *testing.T) {
+	cases, err := LoadDir("testdata/archive")
+	if err != nil {
+		t.Fatalf("LoadDir() returned an error: %v", err)
+	}
+
+	current := Calculator{Rules: rules.Default()}
+
+	t.Run("samme beregning", func(t *testing.T) {
+		want := Summary{Plans: 1}
+		if diff := cmp.Diff(want, Diff(cases, current, current)); diff != "" {
+			t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("mot arkivert utbetaling", func(t *testing.T) {
+		artskoder := []ArtskodeDiff{
+			{
+				Artskode:      "2683",
+				BaselineHours: decimal.NewFromInt(24),
+				Hours:         decimal.NewFromInt(24),
+				BaselineSum:   decimal.RequireFromString("3300.00"),
+				Sum:           decimal.RequireFromString("3366.59"),
+			},
+		}
+		want := Summary{
+			Plans:     1,
+			Changed:   1,
+			Artskoder: artskoder,
+			Diffs: []PlanDiff{
+				{
+					Name:      "nyttarsaften",
+					ID:        "b4ac8e53-9d64-4557-8ef8-d00774ab9c06",
+					Artskoder: artskoder,
+				},
+			},
+		}
+		if diff := cmp.Diff(want, Diff(cases, Calculator{Archived: true}, current)); diff != "" {
+			t.Errorf("Diff() mismatch (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("beregningen feiler", func(t *testing.T) {
+		got := Diff(cases, current, Calculator{})
+		if got.Failed != 1 || got.Changed != 1 || got.Diffs[0].Error == "" || got.Diffs[0].BaselineError != "" {
+			t.Errorf("Diff() = %+v, want the current calculation to fail", got)
+		}
+	})
+}
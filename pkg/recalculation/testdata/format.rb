@@ -1,7 +1,7 @@
 #!/bin/ruby
 # frozen_string_literal: true
 
-# Reads all JSON files in the current directory and format them to a standard
+# Reads the MinWinTid responses in the golden cases and format them to a standard
 
 require 'json'
 
@@ -53,7 +53,7 @@ def format(input)
   input
 end
 
-Dir.glob('pkg/service/testdata/*.json').each do |file|
+Dir.glob('pkg/recalculation/testdata/golden/*/minwintid.json').each do |file|
   payload = JSON.parse(File.read(file))
 
   p file
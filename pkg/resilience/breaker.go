@@ -0,0 +1,121 @@
+package resilience
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrOpen returneres i stedet for å gjøre kallet når breakeren er åpen
+var ErrOpen = errors.New("circuit breaker is open")
+
+type state int
+
+const (
+	closed state = iota
+	open
+	halfOpen
+)
+
+// Breaker stopper kall mot en tjeneste etter Threshold feil på rad. Etter Cooldown slippes ett kall gjennom, og
+// går det bra lukkes breakeren igjen. En nil Breaker, eller en med Threshold 0, slipper alltid gjennom.
+type Breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    state
+	failures int
+	openedAt time.Time
+}
+
+// NewBreaker lager en Breaker for tjenesten name
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		name:      name,
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// Name er navnet på tjenesten breakeren er for
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// Allow sier om et kall kan gjøres nå. Er breakeren åpen returneres ErrOpen.
+func (b *Breaker) Allow() error {
+	if b == nil || b.threshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case open:
+		if b.now().Before(b.openedAt.Add(b.cooldown)) {
+			return fmt.Errorf("%v: %w", b.name, ErrOpen)
+		}
+		// Ett kall slippes gjennom for å se om tjenesten er tilbake
+		b.state = halfOpen
+		return nil
+	case halfOpen:
+		return fmt.Errorf("%v: %w", b.name, ErrOpen)
+	default:
+		return nil
+	}
+}
+
+// Record registrerer utfallet av et kall som ble sluppet gjennom
+func (b *Breaker) Record(success bool) {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = closed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == halfOpen || b.failures >= b.threshold {
+		b.state = open
+		b.openedAt = b.now()
+	}
+}
+
+// Abandon registrerer at et kall som ble sluppet gjennom ble avbrutt av den som gjorde det, og ikke sier noe om
+// tjenesten. Var det kallet som skulle se om tjenesten er tilbake, kan neste kall prøve i stedet.
+func (b *Breaker) Abandon() {
+	if b == nil || b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == halfOpen {
+		b.state = open
+	}
+}
+
+// Open sier om breakeren er åpen, og kall vil bli stoppet
+func (b *Breaker) Open() bool {
+	if b == nil || b.threshold <= 0 {
+		return false
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state == open && b.now().Before(b.openedAt.Add(b.cooldown))
+}
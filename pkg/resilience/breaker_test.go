@@ -0,0 +1,117 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func TestBreaker(t *testing.T) {
+	c := &clock{now: time.Date(2022, 10, 5, 8, 0, 0, 0, time.UTC)}
+	breaker := NewBreaker("MinWinTid", 3, time.Minute)
+	breaker.now = c.Now
+
+	for range 2 {
+		if err := breaker.Allow(); err != nil {
+			t.Fatalf("Allow() before threshold returned an error: %v", err)
+		}
+		breaker.Record(false)
+	}
+	if breaker.Open() {
+		t.Fatal("Open() before threshold = true, want false")
+	}
+
+	breaker.Record(false)
+	if !breaker.Open() {
+		t.Fatal("Open() after threshold = false, want true")
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() while open = %v, want ErrOpen", err)
+	}
+
+	// Etter cooldown slippes ett kall gjennom, og feiler det åpnes breakeren igjen
+	c.now = c.now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() after cooldown returned an error: %v", err)
+	}
+	if err := breaker.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Allow() while half open = %v, want ErrOpen", err)
+	}
+	breaker.Record(false)
+	if !breaker.Open() {
+		t.Fatal("Open() after failed probe = false, want true")
+	}
+
+	// Går kallet etter cooldown bra, lukkes breakeren
+	c.now = c.now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() after cooldown returned an error: %v", err)
+	}
+	breaker.Record(true)
+	if breaker.Open() {
+		t.Fatal("Open() after successful probe = true, want false")
+	}
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() after closing returned an error: %v", err)
+	}
+}
+
+func TestBreakerSuccessResetsFailures(t *testing.T) {
+	breaker := NewBreaker("Vaktor Plan", 2, time.Minute)
+
+	breaker.Record(false)
+	breaker.Record(true)
+	breaker.Record(false)
+	if breaker.Open() {
+		t.Error("Open() after failures that were not in a row = true, want false")
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	for name, breaker := range map[string]*Breaker{
+		"nil":         nil,
+		"threshold 0": NewBreaker("MinWinTid", 0, time.Minute),
+	} {
+		t.Run(name, func(t *testing.T) {
+			for range 10 {
+				breaker.Record(false)
+			}
+			if breaker.Open() {
+				t.Error("Open() = true, want false")
+			}
+			if err := breaker.Allow(); err != nil {
+				t.Errorf("Allow() returned an error: %v", err)
+			}
+		})
+	}
+}
+
+func TestBreakerAbandonedProbe(t *testing.T) {
+	c := &clock{now: time.Date(2022, 10, 5, 8, 0, 0, 0, time.UTC)}
+	breaker := NewBreaker("MinWinTid", 1, time.Minute)
+	breaker.now = c.Now
+
+	breaker.Record(false)
+	c.now = c.now.Add(time.Minute)
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() after cooldown returned an error: %v", err)
+	}
+
+	// Et kall som ble avbrutt sier ikke noe om tjenesten, så neste kall får prøve i stedet
+	breaker.Abandon()
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Allow() after abandoned probe returned an error: %v", err)
+	}
+	breaker.Record(true)
+	if breaker.Open() {
+		t.Error("Open() after successful probe = true, want false")
+	}
+}
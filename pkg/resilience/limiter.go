@@ -0,0 +1,76 @@
+package resilience
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter begrenser hvor mange kall som gjøres i sekundet, med en bøtte som fylles med perSecond kall i sekundet og
+// rommer burst kall. En nil Limiter begrenser ingenting.
+type Limiter struct {
+	perSecond float64
+	burst     float64
+	now       func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter lager en Limiter. Er perSecond 0, returneres nil, og ingenting begrenses.
+func NewLimiter(perSecond float64, burst int) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		perSecond: perSecond,
+		burst:     float64(burst),
+		now:       time.Now,
+		tokens:    float64(burst),
+	}
+}
+
+// reserve tar et kall fra bøtta, og returnerer hvor lenge det må ventes før kallet kan gjøres
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.perSecond)
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.perSecond * float64(time.Second))
+}
+
+// Wait venter til et kall kan gjøres, eller til ctx er ferdig
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	wait := l.reserve()
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,69 @@
+package resilience
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter_reserve(t *testing.T) {
+	c := &clock{now: time.Date(2022, 10, 5, 8, 0, 0, 0, time.UTC)}
+	limiter := NewLimiter(2, 1)
+	limiter.now = c.Now
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		want    time.Duration
+	}{
+		{
+			name: "Første kall går med en gang",
+			want: 0,
+		},
+		{
+			name: "Neste kall må vente et halvt sekund",
+			want: 500 * time.Millisecond,
+		},
+		{
+			name: "Kallet etter det må vente et helt sekund",
+			want: time.Second,
+		},
+		{
+			name:    "Etter en pause er bøtta full igjen",
+			advance: 10 * time.Second,
+			want:    0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c.now = c.now.Add(tt.advance)
+			if got := limiter.reserve(); got != tt.want {
+				t.Errorf("reserve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	limiter := NewLimiter(0.001, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() returned an error: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Wait() with cancelled context returned no error")
+	}
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	if limiter := NewLimiter(0, 1); limiter != nil {
+		t.Errorf("NewLimiter(0) = %v, want nil", limiter)
+	}
+
+	var limiter *Limiter
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Errorf("Wait() on nil Limiter returned an error: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Transport sender requester mot en tjeneste gjennom en Breaker og en Limiter. Feil i forbindelsen og svar med
+// statuskode 5xx teller som feil i breakeren, mens 4xx er feil i requesten og ikke i tjenesten. En request som
+// avbrytes av den som gjorde den, fordi konteksten er kansellert eller har gått ut, teller heller ikke.
+type Transport struct {
+	Base    http.RoundTripper
+	Breaker *Breaker
+	Limiter *Limiter
+	// Timeout er hvor lenge tjenesten får på seg til å svare og sende svaret. Går tiden ut teller det som en feil i
+	// tjenesten, i motsetning til når konteksten til den som gjorde requesten går ut.
+	Timeout time.Duration
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	caller := req.Context()
+	if err := t.Limiter.Wait(caller); err != nil {
+		return nil, err
+	}
+
+	if err := t.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if t.Timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(caller, t.Timeout)
+		req = req.WithContext(ctx)
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		cancel()
+		if errors.Is(err, context.Canceled) || (errors.Is(err, context.DeadlineExceeded) && caller.Err() != nil) {
+			t.Breaker.Abandon()
+		} else {
+			t.Breaker.Record(false)
+		}
+		return nil, err
+	}
+
+	t.Breaker.Record(resp.StatusCode < http.StatusInternalServerError)
+	resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose holder konteksten til requesten i live til svaret er lest
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Wrap sender alle requestene til client gjennom breaker og limiter. Timeouten til client flyttes til Transport, slik
+// at breakeren kan skille mellom en tjeneste som ikke svarer og en request som er avbrutt av den som gjorde den.
+func Wrap(client *http.Client, breaker *Breaker, limiter *Limiter) {
+	client.Transport = &Transport{Base: client.Transport, Breaker: breaker, Limiter: limiter, Timeout: client.Timeout}
+	client.Timeout = 0
+}
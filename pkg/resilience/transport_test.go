@@ -0,0 +1,120 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	var requests int
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	breaker := NewBreaker("MinWinTid", 2, time.Minute)
+	client := &http.Client{}
+	Wrap(client, breaker, nil)
+
+	get := func() error {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	}
+
+	// Svar med 4xx er ikke feil i tjenesten
+	status = http.StatusBadRequest
+	for range 3 {
+		if err := get(); err != nil {
+			t.Fatalf("Get() returned an error: %v", err)
+		}
+	}
+	if breaker.Open() {
+		t.Fatal("Open() after 4xx = true, want false")
+	}
+
+	status = http.StatusInternalServerError
+	for range 2 {
+		if err := get(); err != nil {
+			t.Fatalf("Get() returned an error: %v", err)
+		}
+	}
+	if !breaker.Open() {
+		t.Fatal("Open() after 5xx = false, want true")
+	}
+
+	if err := get(); !errors.Is(err, ErrOpen) {
+		t.Errorf("Get() while open = %v, want ErrOpen", err)
+	}
+	if requests != 5 {
+		t.Errorf("server got %v requests, want 5", requests)
+	}
+}
+
+func TestTransportWhenCallerGivesUp(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		ctx  func() (context.Context, context.CancelFunc)
+		// timeout er hvor lenge tjenesten får på seg til å svare
+		timeout  time.Duration
+		wantOpen bool
+	}{
+		{
+			name: "konteksten blir kansellert",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(10*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+		},
+		{
+			name: "konteksten går ut",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+		},
+		{
+			name: "tjenesten svarer ikke i tide",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			timeout:  10 * time.Millisecond,
+			wantOpen: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := NewBreaker("MinWinTid", 1, time.Minute)
+			client := &http.Client{Timeout: tt.timeout}
+			Wrap(client, breaker, nil)
+
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.Do(req); err == nil {
+				t.Fatal("Do() returned no error")
+			}
+
+			if breaker.Open() != tt.wantOpen {
+				t.Errorf("Open() = %v, want %v", breaker.Open(), tt.wantOpen)
+			}
+		})
+	}
+}
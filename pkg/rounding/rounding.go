@@ -0,0 +1,118 @@
+package rounding
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Per sier om minuttene blir avrundet for hver dag, eller samlet for hele perioden
+type Per string
+
+const (
+	PerPeriod Per = "periode"
+	PerDay    Per = "dag"
+)
+
+// Method sier hvordan minuttene blir avrundet
+type Method string
+
+const (
+	// HalfUp runder halve opp, slik DivRound gjør
+	HalfUp Method = "halv_opp"
+	// HalfEven runder halve til nærmeste partall, også kalt bankers rounding
+	HalfEven Method = "halv_partall"
+	// Truncate runder alltid ned
+	Truncate Method = "ned"
+)
+
+var minutesInHour = decimal.NewFromInt(60)
+
+// Policy er avrundingen for en artskode
+type Policy struct {
+	Artskode string `json:"artskode"`
+	Per      Per    `json:"per"`
+	Method   Method `json:"metode"`
+	// Granularity er hvor mange minutter det rundes av til, enten en del av en time (for eksempel 15 eller 30) eller
+	// hele timer
+	Granularity int64 `json:"minutter"`
+}
+
+// Default er avrundingen som alltid har vært brukt: hele timer for hele perioden, der halve timer rundes opp
+var Default = Policy{
+	Per:         PerPeriod,
+	Method:      HalfUp,
+	Granularity: 60,
+}
+
+// Validate sjekker at avrundingen er gyldig
+func (p Policy) Validate() error {
+	if p.Per != PerPeriod && p.Per != PerDay {
+		return fmt.Errorf("unknown per %q", p.Per)
+	}
+	if p.Method != HalfUp && p.Method != HalfEven && p.Method != Truncate {
+		return fmt.Errorf("unknown metode %q", p.Method)
+	}
+	if p.Granularity <= 0 {
+		return fmt.Errorf("minutter must be positive")
+	}
+	// Timene skal gå opp i hele deler av en time, eller i hele timer
+	if 60%p.Granularity != 0 && p.Granularity%60 != 0 {
+		return fmt.Errorf("minutter must divide an hour or be whole hours, got %v", p.Granularity)
+	}
+	return nil
+}
+
+// Hours runder av minuttene for hver dag i perioden, og returnerer antall timer som skal utbetales og hvor mange
+// minutter som gikk tapt (positivt) eller ble lagt til (negativt) i avrundingen.
+func (p Policy) Hours(minutesPerDay []int64) (decimal.Decimal, int64) {
+	var total int64
+	for _, minutes := range minutesPerDay {
+		total += minutes
+	}
+
+	var rounded int64
+	if p.Per == PerDay {
+		for _, minutes := range minutesPerDay {
+			rounded += p.round(minutes)
+		}
+	} else {
+		rounded = p.round(total)
+	}
+
+	return decimal.NewFromInt(rounded).Div(minutesInHour), total - rounded
+}
+
+// round runder av minutter til nærmeste granularitet, og returnerer antall minutter
+func (p Policy) round(minutes int64) int64 {
+	if minutes < 0 {
+		return -p.round(-minutes)
+	}
+
+	quotient, remainder := minutes/p.Granularity, minutes%p.Granularity
+	switch p.Method {
+	case HalfUp:
+		if 2*remainder >= p.Granularity {
+			quotient++
+		}
+	case HalfEven:
+		if 2*remainder > p.Granularity || (2*remainder == p.Granularity && quotient%2 == 1) {
+			quotient++
+		}
+	}
+
+	return quotient * p.Granularity
+}
+
+// Policies er avrundingen for hver artskode
+type Policies []Policy
+
+// For returnerer avrundingen for en artskode. Artskoder uten egen avrunding bruker Default.
+func (p Policies) For(artskode string) Policy {
+	for _, policy := range p {
+		if policy.Artskode == artskode {
+			return policy
+		}
+	}
+	return Default
+}
@@ -0,0 +1,167 @@
+package rounding
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestPolicy_Hours(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        Policy
+		minutesPerDay []int64
+		wantHours     decimal.Decimal
+		wantResidual  int64
+	}{
+		{
+			name:          "standard runder halve timer opp for hele perioden",
+			policy:        Default,
+			minutesPerDay: []int64{90, 60},
+			wantHours:     decimal.NewFromInt(3),
+			wantResidual:  -30,
+		},
+		{
+			name:          "standard runder ned under en halv time",
+			policy:        Default,
+			minutesPerDay: []int64{89, 60},
+			wantHours:     decimal.NewFromInt(2),
+			wantResidual:  29,
+		},
+		{
+			name:          "per dag runder hver dag for seg",
+			policy:        Policy{Per: PerDay, Method: HalfUp, Granularity: 60},
+			minutesPerDay: []int64{90, 90},
+			wantHours:     decimal.NewFromInt(4),
+			wantResidual:  -60,
+		},
+		{
+			name:          "halv til partall runder ned til partall",
+			policy:        Policy{Per: PerPeriod, Method: HalfEven, Granularity: 60},
+			minutesPerDay: []int64{150},
+			wantHours:     decimal.NewFromInt(2),
+			wantResidual:  30,
+		},
+		{
+			name:          "halv til partall runder opp til partall",
+			policy:        Policy{Per: PerPeriod, Method: HalfEven, Granularity: 60},
+			minutesPerDay: []int64{90},
+			wantHours:     decimal.NewFromInt(2),
+			wantResidual:  -30,
+		},
+		{
+			name:          "halv til partall runder over halvparten opp",
+			policy:        Policy{Per: PerPeriod, Method: HalfEven, Granularity: 60},
+			minutesPerDay: []int64{151},
+			wantHours:     decimal.NewFromInt(3),
+			wantResidual:  -29,
+		},
+		{
+			name:          "ned runder alltid ned",
+			policy:        Policy{Per: PerPeriod, Method: Truncate, Granularity: 60},
+			minutesPerDay: []int64{119},
+			wantHours:     decimal.NewFromInt(1),
+			wantResidual:  59,
+		},
+		{
+			name:          "kvarter",
+			policy:        Policy{Per: PerPeriod, Method: HalfUp, Granularity: 15},
+			minutesPerDay: []int64{98},
+			wantHours:     decimal.NewFromFloat(1.75),
+			wantResidual:  -7,
+		},
+		{
+			name:          "per dag med kvarter",
+			policy:        Policy{Per: PerDay, Method: Truncate, Granularity: 15},
+			minutesPerDay: []int64{29, 29},
+			wantHours:     decimal.NewFromFloat(0.5),
+			wantResidual:  28,
+		},
+		{
+			name:          "negative minutter rundes likt som positive",
+			policy:        Default,
+			minutesPerDay: []int64{-90},
+			wantHours:     decimal.NewFromInt(-2),
+			wantResidual:  30,
+		},
+		{
+			name:          "ingen dager",
+			policy:        Default,
+			minutesPerDay: nil,
+			wantHours:     decimal.Zero,
+			wantResidual:  0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hours, residual := tt.policy.Hours(tt.minutesPerDay)
+			if !hours.Equal(tt.wantHours) {
+				t.Errorf("Hours() hours = %v, want %v", hours, tt.wantHours)
+			}
+			if residual != tt.wantResidual {
+				t.Errorf("Hours() residual = %v, want %v", residual, tt.wantResidual)
+			}
+		})
+	}
+}
+
+func TestPolicies_For(t *testing.T) {
+	policies := Policies{
+		{Artskode: "dag", Per: PerDay, Method: Truncate, Granularity: 30},
+	}
+
+	if got := policies.For("dag"); got != policies[0] {
+		t.Errorf("For() dag = %v, want %v", got, policies[0])
+	}
+	if got := policies.For("helg"); got != Default {
+		t.Errorf("For() helg = %v, want %v", got, Default)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{
+			name:   "standard",
+			policy: Default,
+		},
+		{
+			name:    "ukjent per",
+			policy:  Policy{Per: "uke", Method: HalfUp, Granularity: 60},
+			wantErr: true,
+		},
+		{
+			name:    "ukjent metode",
+			policy:  Policy{Per: PerDay, Method: "opp", Granularity: 60},
+			wantErr: true,
+		},
+		{
+			name:    "mangler minutter",
+			policy:  Policy{Per: PerDay, Method: HalfUp},
+			wantErr: true,
+		},
+		{
+			name:   "kvarter",
+			policy: Policy{Per: PerDay, Method: HalfUp, Granularity: 15},
+		},
+		{
+			name:   "to timer",
+			policy: Policy{Per: PerPeriod, Method: HalfUp, Granularity: 120},
+		},
+		{
+			name:    "minutter som ikke går opp i en time",
+			policy:  Policy{Per: PerDay, Method: HalfUp, Granularity: 45},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,488 @@
+package rules
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"time"
+
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rounding"
+	"github.com/shopspring/decimal"
+)
+
+// defaultRules er reglene fra særavtalen slik de er lagt inn i koden. De kan overstyres ved oppstart.
+//
+//go:embed rules.json
+var defaultRules []byte
+
+const dateFormat = "2006-01-02"
+
+// Clock er et tidspunkt på døgnet, oppgitt som "15:04" i regelfilen. "24:00" er slutten av døgnet.
+type Clock time.Duration
+
+func (c *Clock) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	var hours, minutes int
+	if _, err := fmt.Sscanf(value, "%d:%d", &hours, &minutes); err != nil {
+		return fmt.Errorf("invalid clock %q: %w", value, err)
+	}
+	if hours < 0 || minutes < 0 || minutes > 59 || hours*60+minutes > 24*60 {
+		return fmt.Errorf("invalid clock %q", value)
+	}
+
+	*c = Clock(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute)
+	return nil
+}
+
+// Window er et tidsrom innenfor et døgn
+type Window struct {
+	Begin Clock `json:"fra"`
+	End   Clock `json:"til"`
+}
+
+// Period returnerer tidsrommet på en gitt dato
+func (w Window) Period(date time.Time) models.Period {
+	midnight := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	return models.Period{
+		Begin: midnight.Add(time.Duration(w.Begin)),
+		End:   midnight.Add(time.Duration(w.End)),
+	}
+}
+
+func (w Window) validate() error {
+	if w.End <= w.Begin {
+		return fmt.Errorf("window ends before it begins")
+	}
+	return nil
+}
+
+// Days sier hvilke dager en regel gjelder for
+type Days string
+
+const (
+	Weekdays Days = "hverdag"
+	Weekend  Days = "helg"
+	AllDays  Days = "alle"
+	// Holidays er helligdager i ukedagene. Kan kun brukes for utrykning, siden kronetillegg for helligdager
+	// allerede er skilt ut som egen type vakt.
+	Holidays Days = "helligdag"
+)
+
+// Matches returnerer true hvis regelen gjelder for en helgedag eller en hverdag
+func (d Days) Matches(weekend bool) bool {
+	return d == AllDays || (d == Weekend) == weekend
+}
+
+// Includes returnerer true hvis regelen gjelder for en gitt dato
+func (d Days) Includes(date time.Time) bool {
+	return d.Matches(date.Weekday() == time.Saturday || date.Weekday() == time.Sunday)
+}
+
+func (d Days) validate() error {
+	if d != Weekdays && d != Weekend && d != AllDays {
+		return fmt.Errorf("unknown days %q", d)
+	}
+	return nil
+}
+
+// GuardDutyWindow er et tidsrom der hvilende vakt blir telt med i en gitt type vakt (models.GuardDuty)
+type GuardDutyWindow struct {
+	Window
+	Bucket string `json:"type"`
+	Days   Days   `json:"dager"`
+}
+
+// Holiday er et skjema i MinWinTid som gir helligdagstillegg. Har skjemaet kjernetid, er det kun
+// helligdag etter kjernetiden starter, ellers er det helligdag hele dagen.
+type Holiday struct {
+	FormName  string  `json:"skjema"`
+	Kjernetid *Window `json:"kjernetid,omitempty"`
+}
+
+// Kronetillegg sier hvilken artskode og sats minuttene fra en eller flere typer vakt blir utbetalt med
+type Kronetillegg struct {
+	Buckets  []string `json:"typer"`
+	Days     Days     `json:"dager"`
+	Artskode string   `json:"artskode"`
+	Sats     string   `json:"sats"`
+	// Divisor deler tillegget, for eksempel utbetales helgetillegget med en femtedel av satsen
+	Divisor int64 `json:"deler"`
+	// CountHours sier om timene skal rapporteres på artskoden
+	CountHours bool `json:"tell_timer"`
+}
+
+// Callout sier hvilke tidsrom utrykning blir utbetalt for, og med hvilken artskode og sats. Reglene brukes i
+// rekkefølge, og hvert minutt med utrykning blir utbetalt etter den første regelen som dekker det.
+type Callout struct {
+	Days     Days     `json:"dager"`
+	Windows  []Window `json:"vinduer"`
+	Artskode string   `json:"artskode"`
+	Sats     string   `json:"sats"`
+}
+
+// AnnualHoursAgreement er en avtale om et annet antall årstimer enn normalen, for eksempel for turnus. Avtalen
+// gjelder for dager med et av skjemaene eller en av stillingskodene.
+type AnnualHoursAgreement struct {
+	Description    string   `json:"beskrivelse"`
+	FormNames      []string `json:"skjema"`
+	Stillingskoder []string `json:"stillingskoder"`
+	AnnualHours    int64    `json:"arstimer"`
+}
+
+func (a AnnualHoursAgreement) matches(day models.TimeSheet) bool {
+	return slices.Contains(a.FormNames, day.FormName) || slices.Contains(a.Stillingskoder, day.Stillingskode)
+}
+
+// Overtime er parameterne for overtidstillegget under vakt. Timelønnen er årslønnen delt på årstimene, og
+// tillegget er timelønnen ganget med faktoren for tidsrommet, delt på Divisor.
+type Overtime struct {
+	AnnualHours int64 `json:"arstimer"`
+	// Multiplier50 brukes for hvilende vakt på dagtid i ukedagene
+	Multiplier50 decimal.Decimal `json:"faktor_50"`
+	// Multiplier100 brukes for hvilende vakt på kveld, natt, i helgen og på helligdager
+	Multiplier100 decimal.Decimal `json:"faktor_100"`
+	// Divisor deler tillegget, hvilende vakt utbetales med en femtedel av overtiden
+	Divisor    int64                  `json:"deler"`
+	Agreements []AnnualHoursAgreement `json:"arstimeavtaler"`
+}
+
+// AnnualHoursFor returnerer årstimene som gjelder for en dag, etter avtalen dagen faller inn under
+func (o Overtime) AnnualHoursFor(day models.TimeSheet) int64 {
+	for _, agreement := range o.Agreements {
+		if agreement.matches(day) {
+			return agreement.AnnualHours
+		}
+	}
+	return o.AnnualHours
+}
+
+func (o Overtime) validate() error {
+	if o.AnnualHours <= 0 {
+		return fmt.Errorf("arstimer must be positive")
+	}
+	if !o.Multiplier50.IsPositive() || !o.Multiplier100.IsPositive() {
+		return fmt.Errorf("faktor_50 and faktor_100 must be positive")
+	}
+	if o.Divisor <= 0 {
+		return fmt.Errorf("deler must be positive")
+	}
+	for i, agreement := range o.Agreements {
+		if agreement.AnnualHours <= 0 {
+			return fmt.Errorf("arstimeavtaler[%d]: arstimer must be positive", i)
+		}
+		if len(agreement.FormNames) == 0 && len(agreement.Stillingskoder) == 0 {
+			return fmt.Errorf("arstimeavtaler[%d]: missing skjema or stillingskoder", i)
+		}
+	}
+	return nil
+}
+
+// defaultGuardDutyPattern kjenner igjen BV som eget ord i begrunnelsen for overtiden, slik at ord som tilfeldigvis
+// inneholder bv ikke regnes med
+var defaultGuardDutyPattern = regexp.MustCompile(`(?i)\bbv\b`)
+
+// GuardDutyMarking sier hvordan overtid som er merket som beredskapsvakt (BV) kjennes igjen i MinWinTid. Overtiden
+// er merket når overtidskoden er en av kodene, eller begrunnelsen passer med mønsteret. Er verken koder eller
+// mønster oppgitt, brukes defaultGuardDutyPattern. Mønsteret kompileres når reglene leses.
+type GuardDutyMarking struct {
+	Codes   []string `json:"koder"`
+	Pattern string   `json:"monster"`
+
+	pattern *regexp.Regexp
+}
+
+// Match returnerer koden eller teksten som gjør at en overtidsstempling regnes som utrykning
+func (m GuardDutyMarking) Match(stempling models.MWTStempling) (string, bool) {
+	if stempling.OvertidKode != "" && slices.Contains(m.Codes, stempling.OvertidKode) {
+		return stempling.OvertidKode, true
+	}
+
+	pattern := m.pattern
+	if pattern == nil && m.Pattern == "" && len(m.Codes) == 0 {
+		// Merkingen er ikke lest fra en regelfil, og har verken koder eller mønster
+		pattern = defaultGuardDutyPattern
+	}
+	if pattern == nil {
+		return "", false
+	}
+
+	if match := pattern.FindString(stempling.OvertidBegrunnelse); match != "" {
+		return match, true
+	}
+	return "", false
+}
+
+func (m *GuardDutyMarking) validate() error {
+	switch {
+	case m.Pattern != "":
+		pattern, err := regexp.Compile(m.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid monster: %w", err)
+		}
+		m.pattern = pattern
+	case len(m.Codes) == 0:
+		m.pattern = defaultGuardDutyPattern
+	}
+	return nil
+}
+
+// Rules er reglene fra særavtalen som gjelder fra og med en gitt dato
+type Rules struct {
+	// Version er navnet på versjonen, og blir rapportert sammen med utbetalingen
+	Version       string        `json:"versjon"`
+	EffectiveFrom string        `json:"gyldig_fra"`
+	Description   string        `json:"beskrivelse"`
+	Satser        models.Satser `json:"satser"`
+	// RequireGuardDutyMarking betyr at overtid kun regnes som utrykning når den er merket med BV i MinWinTid
+	RequireGuardDutyMarking bool             `json:"overtid_krever_bv"`
+	GuardDutyMarking        GuardDutyMarking `json:"bv_merking"`
+	Overtime                Overtime         `json:"overtid"`
+
+	GuardDutyWindows []GuardDutyWindow `json:"vaktvinduer"`
+	Kjernetid        Window            `json:"kjernetid"`
+	Holidays         []Holiday         `json:"helligdager"`
+	Kronetillegg     []Kronetillegg    `json:"kronetillegg"`
+	Callouts         []Callout         `json:"utrykning"`
+	Rounding         rounding.Policies `json:"avrunding"`
+
+	// CalloutMinimum er minste antall minutter en utrykning blir utbetalt med
+	CalloutMinimum int64 `json:"utrykning_minimum_minutter"`
+	// CalloutMergeGap er hvor mange minutter det kan være mellom to utrykninger for at de regnes som en
+	CalloutMergeGap int64 `json:"utrykning_mellomrom_minutter"`
+
+	effectiveFrom time.Time
+}
+
+// Holiday returnerer helligdagen for et skjema, hvis skjemaet er en helligdag
+func (r Rules) Holiday(formName string) (Holiday, bool) {
+	for _, holiday := range r.Holidays {
+		if holiday.FormName == formName {
+			return holiday, true
+		}
+	}
+	return Holiday{}, false
+}
+
+// IsFullDayHoliday returnerer true hvis skjemaet er helligdag hele dagen
+func (r Rules) IsFullDayHoliday(formName string) bool {
+	holiday, ok := r.Holiday(formName)
+	return ok && holiday.Kjernetid == nil
+}
+
+// KjernetidFor returnerer kjernetiden for et skjema. Helligdager som starter midt på dagen har sin egen kjernetid.
+func (r Rules) KjernetidFor(formName string) Window {
+	if holiday, ok := r.Holiday(formName); ok && holiday.Kjernetid != nil {
+		return *holiday.Kjernetid
+	}
+	return r.Kjernetid
+}
+
+func (r *Rules) validate() error {
+	if r.Version == "" {
+		return fmt.Errorf("missing versjon")
+	}
+
+	for _, sats := range []decimal.Decimal{r.Satser.Dag, r.Satser.Natt, r.Satser.Helg, r.Satser.Utvidet} {
+		if !sats.IsPositive() {
+			return fmt.Errorf("satser must be positive")
+		}
+	}
+
+	if err := r.GuardDutyMarking.validate(); err != nil {
+		return fmt.Errorf("bv_merking: %w", err)
+	}
+
+	if err := r.Overtime.validate(); err != nil {
+		return fmt.Errorf("overtid: %w", err)
+	}
+
+	from, err := time.Parse(dateFormat, r.EffectiveFrom)
+	if err != nil {
+		return fmt.Errorf("parsing gyldig_fra: %w", err)
+	}
+	r.effectiveFrom = from
+
+	for i, window := range r.GuardDutyWindows {
+		if _, ok := (&models.GuardDuty{}).Bucket(window.Bucket); !ok {
+			return fmt.Errorf("vaktvinduer[%d]: unknown type %q", i, window.Bucket)
+		}
+		if err := window.Days.validate(); err != nil {
+			return fmt.Errorf("vaktvinduer[%d]: %w", i, err)
+		}
+		if err := window.validate(); err != nil {
+			return fmt.Errorf("vaktvinduer[%d]: %w", i, err)
+		}
+	}
+
+	if err := r.Kjernetid.validate(); err != nil {
+		return fmt.Errorf("kjernetid: %w", err)
+	}
+
+	for i, holiday := range r.Holidays {
+		if holiday.Kjernetid != nil {
+			if err := holiday.Kjernetid.validate(); err != nil {
+				return fmt.Errorf("helligdager[%d]: %w", i, err)
+			}
+		}
+	}
+
+	for i := range r.Kronetillegg {
+		tillegg := &r.Kronetillegg[i]
+		if len(tillegg.Buckets) == 0 {
+			return fmt.Errorf("kronetillegg[%d]: missing typer", i)
+		}
+		for _, bucket := range tillegg.Buckets {
+			if _, ok := (&models.GuardDuty{}).Bucket(bucket); !ok {
+				return fmt.Errorf("kronetillegg[%d]: unknown type %q", i, bucket)
+			}
+		}
+		if err := tillegg.Days.validate(); err != nil {
+			return fmt.Errorf("kronetillegg[%d]: %w", i, err)
+		}
+		if err := validateArtskodeAndSats(tillegg.Artskode, tillegg.Sats); err != nil {
+			return fmt.Errorf("kronetillegg[%d]: %w", i, err)
+		}
+		if tillegg.Divisor == 0 {
+			tillegg.Divisor = 1
+		}
+		if tillegg.Divisor < 0 {
+			return fmt.Errorf("kronetillegg[%d]: deler must be positive", i)
+		}
+	}
+
+	for i, callout := range r.Callouts {
+		if callout.Days != Holidays {
+			if err := callout.Days.validate(); err != nil {
+				return fmt.Errorf("utrykning[%d]: %w", i, err)
+			}
+		}
+		for _, window := range callout.Windows {
+			if err := window.validate(); err != nil {
+				return fmt.Errorf("utrykning[%d]: %w", i, err)
+			}
+		}
+		if err := validateArtskodeAndSats(callout.Artskode, callout.Sats); err != nil {
+			return fmt.Errorf("utrykning[%d]: %w", i, err)
+		}
+	}
+
+	if r.CalloutMinimum < 0 || r.CalloutMergeGap < 0 {
+		return fmt.Errorf("utrykning_minimum_minutter and utrykning_mellomrom_minutter can not be negative")
+	}
+
+	for i, policy := range r.Rounding {
+		if _, ok := (&models.Artskoder{}).Artskode(policy.Artskode); !ok {
+			return fmt.Errorf("avrunding[%d]: unknown artskode %q", i, policy.Artskode)
+		}
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("avrunding[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func validateArtskodeAndSats(artskode, sats string) error {
+	if _, ok := (&models.Artskoder{}).Artskode(artskode); !ok {
+		return fmt.Errorf("unknown artskode %q", artskode)
+	}
+	if _, ok := (models.Satser{}).Sats(sats); !ok {
+		return fmt.Errorf("unknown sats %q", sats)
+	}
+	return nil
+}
+
+// Versions er alle versjonene av reglene, sortert etter når de trer i kraft
+type Versions []Rules
+
+// At returnerer reglene som gjelder på en gitt dato
+func (v Versions) At(date time.Time) (Rules, error) {
+	for i := len(v) - 1; i >= 0; i-- {
+		if !date.Before(v[i].effectiveFrom) {
+			return v[i], nil
+		}
+	}
+	return Rules{}, fmt.Errorf("no rules in effect at %v", date.Format(dateFormat))
+}
+
+// Pin låser beregningen til en gitt versjon, uavhengig av når vaktperioden var. Brukes i tester og
+// for å beregne en periode på nytt med andre regler enn de som gjaldt da.
+func (v Versions) Pin(name string) (Versions, error) {
+	for _, version := range v {
+		if version.Version == name {
+			version.effectiveFrom = time.Time{}
+			return Versions{version}, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown version %v", name)
+}
+
+// Parse leser og validerer en regelfil
+func Parse(data []byte) (Versions, error) {
+	var file struct {
+		Versions Versions `json:"versjoner"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	if len(file.Versions) == 0 {
+		return nil, fmt.Errorf("no versions of the rules")
+	}
+
+	for i := range file.Versions {
+		if err := file.Versions[i].validate(); err != nil {
+			return nil, fmt.Errorf("version %v: %w", file.Versions[i].EffectiveFrom, err)
+		}
+	}
+
+	slices.SortFunc(file.Versions, func(a, b Rules) int {
+		return a.effectiveFrom.Compare(b.effectiveFrom)
+	})
+	for i := 1; i < len(file.Versions); i++ {
+		if file.Versions[i].effectiveFrom.Equal(file.Versions[i-1].effectiveFrom) {
+			return nil, fmt.Errorf("two versions are effective from %v", file.Versions[i].EffectiveFrom)
+		}
+	}
+
+	names := make(map[string]bool)
+	for _, version := range file.Versions {
+		if names[version.Version] {
+			return nil, fmt.Errorf("two versions are named %v", version.Version)
+		}
+		names[version.Version] = true
+	}
+
+	return file.Versions, nil
+}
+
+// Load leser reglene fra en fil. Er ingen fil oppgitt, brukes reglene som er lagt inn i koden.
+func Load(path string) (Versions, error) {
+	if path == "" {
+		return Parse(defaultRules)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(data)
+}
+
+// Default returnerer reglene som er lagt inn i koden
+func Default() Versions {
+	versions, err := Parse(defaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return versions
+}
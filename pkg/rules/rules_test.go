@@ -0,0 +1,350 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+)
+
+func TestDefault(t *testing.T) {
+	versions, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() returned an error: %v", err)
+	}
+
+	rules, err := versions.At(time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("At() returned an error: %v", err)
+	}
+
+	kjernetid := rules.KjernetidFor("BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)").Period(time.Date(2022, 10, 3, 0, 0, 0, 0, time.UTC))
+	want := models.Period{
+		Begin: time.Date(2022, 10, 3, 9, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 10, 3, 14, 30, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(want, kjernetid); diff != "" {
+		t.Errorf("KjernetidFor() mismatch (-want +got):\n%s", diff)
+	}
+
+	kjernetid = rules.KjernetidFor("Nyttårsaften 1000-1200 *").Period(time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+	want = models.Period{
+		Begin: time.Date(2022, 12, 31, 10, 0, 0, 0, time.UTC),
+		End:   time.Date(2022, 12, 31, 12, 0, 0, 0, time.UTC),
+	}
+	if diff := cmp.Diff(want, kjernetid); diff != "" {
+		t.Errorf("KjernetidFor() mismatch (-want +got):\n%s", diff)
+	}
+
+	if !rules.IsFullDayHoliday("Helligdag") {
+		t.Errorf("IsFullDayHoliday() Helligdag should be a holiday the whole day")
+	}
+	if rules.IsFullDayHoliday("Julaften 0800-1200 *") {
+		t.Errorf("IsFullDayHoliday() Julaften should only be a holiday after kjernetid")
+	}
+	if _, ok := rules.Holiday("BV Lørdag IKT"); ok {
+		t.Errorf("Holiday() BV Lørdag IKT is not a holiday")
+	}
+
+	// Fra 1. februar 2023 ble det krav om å merke overtid ved utrykning med BV
+	before, err := versions.At(time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil || before.RequireGuardDutyMarking {
+		t.Errorf("At() before 2023-02-01 got = %v (%v), should not require BV", before.Version, err)
+	}
+	after, err := versions.At(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || !after.RequireGuardDutyMarking {
+		t.Errorf("At() from 2023-02-01 got = %v (%v), should require BV", after.Version, err)
+	}
+}
+
+func TestVersions_At(t *testing.T) {
+	versions, err := Parse([]byte(`{"versjoner": [
+		{"gyldig_fra": "2024-05-01", "versjon": "2024-05", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "15:00"}},
+		{"gyldig_fra": "2021-01-01", "versjon": "2021-01", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"}}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse() returned an error: %v", err)
+	}
+
+	pinned, err := versions.Pin("2021-01")
+	if err != nil {
+		t.Fatalf("Pin() returned an error: %v", err)
+	}
+	if got, err := pinned.At(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil || got.Version != "2021-01" {
+		t.Errorf("Pin() got = %v (%v), want 2021-01", got.Version, err)
+	}
+	if _, err := versions.Pin("2022-01"); err == nil {
+		t.Errorf("Pin() should return an error for an unknown version")
+	}
+
+	tests := []struct {
+		name    string
+		date    time.Time
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "før første versjon",
+			date:    time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC),
+			wantErr: true,
+		},
+		{
+			name: "første versjon",
+			date: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+			want: "2021-01",
+		},
+		{
+			name: "dagen før ny versjon",
+			date: time.Date(2024, 4, 30, 23, 59, 0, 0, time.UTC),
+			want: "2021-01",
+		},
+		{
+			name: "ny versjon",
+			date: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+			want: "2024-05",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := versions.At(tt.date)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("At() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if got.Version != tt.want {
+				t.Errorf("At() got = %v, want %v", got.Version, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		want  string
+	}{
+		{
+			name:  "ingen versjoner",
+			rules: `{"versjoner": []}`,
+			want:  "no versions",
+		},
+		{
+			name:  "mangler navn på versjonen",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"}}]}`,
+			want:  "missing versjon",
+		},
+		{
+			name:  "mangler satser",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25}, "kjernetid": {"fra": "09:00", "til": "14:30"}}]}`,
+			want:  "satser",
+		},
+		{
+			name:  "mangler overtid",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "kjernetid": {"fra": "09:00", "til": "14:30"}}]}`,
+			want:  "overtid: arstimer",
+		},
+		{
+			name: "årstimeavtale uten skjema eller stillingskoder",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5, "arstimeavtaler": [{"arstimer": 1750}]}}]}`,
+			want: "arstimeavtaler[0]",
+		},
+		{
+			name: "to versjoner med samme navn",
+			rules: `{"versjoner": [
+				{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"}},
+				{"gyldig_fra": "2023-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "15:00"}}
+			]}`,
+			want: "two versions are named",
+		},
+		{
+			name:  "ugyldig dato",
+			rules: `{"versjoner": [{"gyldig_fra": "01.01.2021", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"}}]}`,
+			want:  "gyldig_fra",
+		},
+		{
+			name:  "ugyldig klokkeslett",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "24:30"}}]}`,
+			want:  "invalid clock",
+		},
+		{
+			name:  "tidsrom som slutter før det starter",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "14:30", "til": "09:00"}}]}`,
+			want:  "kjernetid",
+		},
+		{
+			name: "ukjent type vakt",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"vaktvinduer": [{"type": "hvilende0507", "dager": "alle", "fra": "05:00", "til": "07:00"}]}]}`,
+			want: "unknown type",
+		},
+		{
+			name: "ukjente dager",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"vaktvinduer": [{"type": "hvilende0006", "dager": "mandag", "fra": "00:00", "til": "06:00"}]}]}`,
+			want: "unknown days",
+		},
+		{
+			name: "helligdag kan kun brukes for utrykning",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"kronetillegg": [{"typer": ["hvilende0620"], "dager": "helligdag", "artskode": "dag", "sats": "dag"}]}]}`,
+			want: "kronetillegg[0]: unknown days",
+		},
+		{
+			name: "ugyldig mønster for BV",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"bv_merking": {"monster": "(bv"}}]}`,
+			want: "bv_merking",
+		},
+		{
+			name: "negativ minste utrykning",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"utrykning_minimum_minutter": -60}]}`,
+			want: "utrykning_minimum_minutter",
+		},
+		{
+			name: "ukjent artskode",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"kronetillegg": [{"typer": ["hvilende0006"], "dager": "alle", "artskode": "2686", "sats": "natt"}]}]}`,
+			want: "unknown artskode",
+		},
+		{
+			name: "ukjent sats",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"utrykning": [{"dager": "helg", "vinduer": [{"fra": "00:00", "til": "24:00"}], "artskode": "utrykning", "sats": "ekstra"}]}]}`,
+			want: "unknown sats",
+		},
+		{
+			name: "ugyldig avrunding",
+			rules: `{"versjoner": [{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"},
+				"avrunding": [{"artskode": "dag", "per": "uke", "metode": "halv_opp", "minutter": 60}]}]}`,
+			want: "avrunding",
+		},
+		{
+			name: "to versjoner fra samme dato",
+			rules: `{"versjoner": [
+				{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "14:30"}},
+				{"gyldig_fra": "2021-01-01", "versjon": "v", "satser": {"0620": 15, "2006": 25, "helg": 65, "skift": 25}, "overtid": {"arstimer": 1850, "faktor_50": 1.5, "faktor_100": 2, "deler": 5}, "kjernetid": {"fra": "09:00", "til": "15:00"}}
+			]}`,
+			want: "two versions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.rules))
+			if err == nil {
+				t.Fatalf("Parse() should return an error")
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Parse() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestOvertime_AnnualHoursFor(t *testing.T) {
+	overtime := Overtime{
+		AnnualHours: 1850,
+		Agreements: []AnnualHoursAgreement{
+			{FormNames: []string{"Turnus 35,5t"}, AnnualHours: 1750},
+			{Stillingskoder: []string{"1234"}, AnnualHours: 1700},
+		},
+	}
+
+	tests := []struct {
+		name string
+		day  models.TimeSheet
+		want int64
+	}{
+		{
+			name: "uten avtale",
+			day:  models.TimeSheet{FormName: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)", Stillingskode: "1364"},
+			want: 1850,
+		},
+		{
+			name: "avtale for skjema",
+			day:  models.TimeSheet{FormName: "Turnus 35,5t", Stillingskode: "1364"},
+			want: 1750,
+		},
+		{
+			name: "avtale for stillingskode",
+			day:  models.TimeSheet{FormName: "Helligdag", Stillingskode: "1234"},
+			want: 1700,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := overtime.AnnualHoursFor(tt.day); got != tt.want {
+				t.Errorf("AnnualHoursFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardDutyMarking_Match(t *testing.T) {
+	tests := []struct {
+		name      string
+		marking   GuardDutyMarking
+		stempling models.MWTStempling
+		want      string
+		wantMatch bool
+	}{
+		{
+			name:      "standard kjenner igjen BV",
+			stempling: models.MWTStempling{OvertidBegrunnelse: "Oppringt vakt, feilsøk, BV"},
+			want:      "BV",
+			wantMatch: true,
+		},
+		{
+			name:      "standard kjenner igjen bv med små bokstaver",
+			stempling: models.MWTStempling{OvertidBegrunnelse: "bv - utrykning"},
+			want:      "bv",
+			wantMatch: true,
+		},
+		{
+			name:      "standard kjenner ikke igjen bv inni et ord",
+			stempling: models.MWTStempling{OvertidBegrunnelse: "Obviously not guard duty"},
+		},
+		{
+			name:      "kode",
+			marking:   GuardDutyMarking{Codes: []string{"42"}},
+			stempling: models.MWTStempling{OvertidKode: "42"},
+			want:      "42",
+			wantMatch: true,
+		},
+		{
+			name:      "kun koder bruker ikke standard mønster",
+			marking:   GuardDutyMarking{Codes: []string{"42"}},
+			stempling: models.MWTStempling{OvertidKode: "7", OvertidBegrunnelse: "BV"},
+		},
+		{
+			name:      "eget mønster",
+			marking:   GuardDutyMarking{Pattern: `(?i)beredskap(svakt)?`},
+			stempling: models.MWTStempling{OvertidBegrunnelse: "Utrykning på beredskapsvakt"},
+			want:      "beredskapsvakt",
+			wantMatch: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.marking.validate(); err != nil {
+				t.Fatalf("validate() returned an error: %v", err)
+			}
+
+			got, ok := tt.marking.Match(tt.stempling)
+			if got != tt.want || ok != tt.wantMatch {
+				t.Errorf("Match() = %q, %v, want %q, %v", got, ok, tt.want, tt.wantMatch)
+			}
+		})
+	}
+
+	// Match kompilerer ikke mønsteret selv, så et ugyldig mønster som ikke er lest fra en regelfil gir ingen panikk
+	if _, ok := (GuardDutyMarking{Pattern: "(bv"}).Match(models.MWTStempling{OvertidBegrunnelse: "(bv"}); ok {
+		t.Errorf("Match() should not match with a pattern that is not compiled")
+	}
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+// AdminTokens er tokenene operatørene bruker mot admin-endepunktene, der nøkkelen er navnet som føres i
+// revisjonsloggen
+type AdminTokens map[string]string
+
+// ParseAdminTokens leser tokens på formen "navn:token,navn:token"
+func ParseAdminTokens(spec string) (AdminTokens, error) {
+	tokens := make(AdminTokens)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, token, ok := strings.Cut(entry, ":")
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("admin token for %q must be on the form name:token", name)
+		}
+		if _, ok := tokens[name]; ok {
+			return nil, fmt.Errorf("admin token %q is given twice", name)
+		}
+		tokens[name] = token
+	}
+
+	return tokens, nil
+}
+
+// authenticate returnerer navnet på operatøren som eier bearer-tokenet i requesten
+func (t AdminTokens) authenticate(r *http.Request) (string, bool) {
+	token, ok := requestBearerToken(r)
+	if !ok {
+		return "", false
+	}
+
+	for name, want := range t {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(want)) == 1 {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// requestBearerToken returnerer tokenet i Authorization-headeren til requesten
+func requestBearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token, ok && token != ""
+}
+
+// requireAdmin slipper bare gjennom requester med et gyldig admin-token, og gir videre hvem som gjør handlingen
+func (h Handler) requireAdmin(next func(w http.ResponseWriter, r *http.Request, actor string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name, ok := h.AdminTokens.authenticate(r)
+		if !ok {
+			http.Error(w, "Error: unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next(w, r, "admin:"+name)
+	}
+}
+
+// RegisterAdmin legger admin-endepunktene til i mux. Uten AdminTokens legges ingen endepunkter til.
+func (h Handler) RegisterAdmin(mux *http.ServeMux) {
+	if len(h.AdminTokens) == 0 {
+		return
+	}
+
+	mux.HandleFunc("GET /admin/plans", h.requireAdmin(h.adminListPlans))
+	mux.HandleFunc("GET /admin/plans/{id}", h.requireAdmin(h.adminShowPlan))
+	mux.HandleFunc("POST /admin/plans/{id}/retry", h.requireAdmin(h.adminRetryPlan))
+	mux.HandleFunc("POST /admin/plans/{id}/cancel", h.requireAdmin(h.adminCancelPlan))
+	mux.HandleFunc("POST /admin/plans/{id}/recalculate", h.requireAdmin(h.adminRecalculatePlan))
+}
+
+// adminPlan er en vaktperiode slik operatørene ser den. Identen er med vilje ikke med.
+type adminPlan struct {
+	ID          uuid.UUID     `json:"id"`
+	PeriodBegin string        `json:"period_begin"`
+	PeriodEnd   string        `json:"period_end"`
+	PostedAt    *time.Time    `json:"posted_at,omitempty"`
+	LastAttempt *adminAttempt `json:"last_attempt,omitempty"`
+}
+
+type adminAttempt struct {
+	At time.Time `json:"at"`
+	attempt
+}
+
+func toAdminPlan(row gensql.Beredskapsvakt) adminPlan {
+	plan := adminPlan{
+		ID:          row.ID,
+		PeriodBegin: row.PeriodBegin.Format(time.DateOnly),
+		PeriodEnd:   row.PeriodEnd.Format(time.DateOnly),
+	}
+	if row.PostedAt.Valid {
+		plan.PostedAt = &row.PostedAt.Time
+	}
+	if row.LastAttemptAt.Valid {
+		plan.LastAttempt = &adminAttempt{
+			At: row.LastAttemptAt.Time,
+			attempt: attempt{
+				Status:  row.LastAttemptStatus.String,
+				Message: row.LastAttemptMessage.String,
+			},
+		}
+	}
+	return plan
+}
+
+// adminListPlans lister vaktperiodene som venter på beregning, eventuelt bare for én ident
+func (h Handler) adminListPlans(w http.ResponseWriter, r *http.Request, _ string) {
+	var rows []gensql.Beredskapsvakt
+	var err error
+	if ident := r.URL.Query().Get("ident"); ident != "" {
+		rows, err = h.Queries.ListBeredskapsvakterByIdentHash(r.Context(), sql.NullString{String: h.Keyring.Hash(ident), Valid: true})
+	} else {
+		rows, err = h.Queries.ListBeredskapsvakter(r.Context())
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Error when listing beredskapsvakter", zap.Error(err))
+		return
+	}
+
+	plans := make([]adminPlan, 0, len(rows))
+	for _, row := range rows {
+		plans = append(plans, toAdminPlan(row))
+	}
+
+	writeJSON(h, w, plans)
+}
+
+// adminShowPlan viser én vaktperiode, også når den er utbetalt, slik at operatørene kan se utfallet av en beregning
+func (h Handler) adminShowPlan(w http.ResponseWriter, r *http.Request, _ string) {
+	row, ok := h.adminGetPlan(w, r)
+	if !ok {
+		return
+	}
+
+	writeJSON(h, w, toAdminPlan(row))
+}
+
+// adminRetryPlan beregner en vaktperiode som venter på beregning med en gang
+func (h Handler) adminRetryPlan(w http.ResponseWriter, r *http.Request, actor string) {
+	row, ok := h.adminGetPlan(w, r)
+	if !ok {
+		return
+	}
+	if row.PostedAt.Valid {
+		http.Error(w, "Error: plan is already posted, use recalculate", http.StatusConflict)
+		return
+	}
+
+	h.adminRunPlan(w, actor, auditActionRetryPlan, row, false)
+}
+
+// adminRecalculatePlan beregner en vaktperiode som allerede er utbetalt på nytt, og sender den nye utbetalingen
+func (h Handler) adminRecalculatePlan(w http.ResponseWriter, r *http.Request, actor string) {
+	row, ok := h.adminGetPlan(w, r)
+	if !ok {
+		return
+	}
+	if !row.PostedAt.Valid {
+		http.Error(w, "Error: plan is not posted yet, use retry", http.StatusConflict)
+		return
+	}
+
+	h.adminRunPlan(w, actor, auditActionRecalculatePlan, row, true)
+}
+
+// adminRunPlan fører handlingen i revisjonsloggen, og beregner vaktperioden slik den ellers ville blitt beregnet.
+// Beregningen kan ta lengre tid enn forespørselen får, så den gjøres i bakgrunnen, og utfallet lagres som siste forsøk
+// på vaktperioden og i revisjonsloggen. Beregnes vaktperioden allerede, svares det med 409.
+func (h Handler) adminRunPlan(w http.ResponseWriter, actor, action string, row gensql.Beredskapsvakt, posted bool) {
+	beredskapsvakt, err := openBeredskapsvakt(h, row)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Failed while decrypting beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+		return
+	}
+
+	claimed, err := claimPlan(h, row.ID, posted)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Failed while claiming beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+		return
+	}
+	if !claimed {
+		http.Error(w, "Error: plan is being calculated or has changed, try again later", http.StatusConflict)
+		return
+	}
+
+	if err := audit(h.Context, h.Queries, actor, action, row.ID, ""); err != nil {
+		releasePlan(h, row.ID)
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Failed while writing audit log", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+		return
+	}
+
+	h.Log.Info("Admin is running beredskapsvakt", zap.String("actor", actor), zap.String("action", action), zap.String(vaktplanId, row.ID.String()))
+	h.inBackground(func(handler Handler) {
+		result := runTransaction(handler, newTimesheets(handler, []models.Beredskapsvakt{beredskapsvakt}), beredskapsvakt, posted)
+		auditAttempt(handler, actor, action, row.ID, result)
+	})
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// auditAttempt fører utfallet av en beregning en operatør har startet i revisjonsloggen, ved siden av handlingen
+func auditAttempt(handler Handler, actor, action string, id uuid.UUID, result attempt) {
+	details := fmt.Sprintf("%v: %v", action, result.Status)
+	if result.Message != "" {
+		details = fmt.Sprintf("%v: %v", details, result.Message)
+	}
+
+	handler.Log.Info("Admin run of beredskapsvakt finished", zap.String("actor", actor), zap.String("action", action), zap.String("status", result.Status), zap.String(vaktplanId, id.String()))
+	if err := audit(handler.Context, handler.Queries, actor, auditActionPlanAttempt, id, details); err != nil {
+		handler.Log.Error("Failed while writing audit log", zap.Error(err), zap.String(vaktplanId, id.String()))
+	}
+}
+
+// cancelMessage er meldingen Vaktor Plan får når en operatør avbryter en vaktperiode
+func cancelMessage(reason string) string {
+	return fmt.Sprintf("Vaktperioden er avbrutt i Vaktor Lønn: %v", reason)
+}
+
+// adminCancelPlan avbryter en vaktperiode som venter på beregning. Vaktor Plan får beskjed med grunnen, før
+// vaktperioden slettes.
+func (h Handler) adminCancelPlan(w http.ResponseWriter, r *http.Request, actor string) {
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusBadRequest)
+		return
+	}
+	if strings.TrimSpace(body.Reason) == "" {
+		http.Error(w, "Error: reason is required", http.StatusBadRequest)
+		return
+	}
+
+	row, ok := h.adminGetPlan(w, r)
+	if !ok {
+		return
+	}
+	if row.PostedAt.Valid {
+		http.Error(w, "Error: plan is already posted", http.StatusConflict)
+		return
+	}
+
+	bearerToken, err := h.BearerClient.GenerateBearerToken()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Problem generating bearer token", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+		return
+	}
+
+	message := cancelMessage(body.Reason)
+	if err := postError(h, models.Beredskapsvakt{ID: row.ID}, message, bearerToken); err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusBadGateway)
+		h.Log.Error("Failed while notifying Vaktor Plan about cancelled beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+		return
+	}
+
+	if err := deleteAuditedPlan(h, row.ID, actor, auditActionCancelPlan, body.Reason); err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Failed while cancelling beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+		return
+	}
+
+	h.Log.Info("Admin cancelled beredskapsvakt", zap.String("actor", actor), zap.String(vaktplanId, row.ID.String()))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminGetPlan henter vaktperioden i stien, og svarer selv hvis den ikke finnes
+func (h Handler) adminGetPlan(w http.ResponseWriter, r *http.Request) (gensql.Beredskapsvakt, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusBadRequest)
+		return gensql.Beredskapsvakt{}, false
+	}
+
+	row, err := h.Queries.GetBeredskapsvakt(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Error: plan not found", http.StatusNotFound)
+		return gensql.Beredskapsvakt{}, false
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Error when getting beredskapsvakt", zap.Error(err), zap.String(vaktplanId, id.String()))
+		return gensql.Beredskapsvakt{}, false
+	}
+
+	return row, true
+}
+
+func writeJSON(h Handler, w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.Log.Error("Error when writing response", zap.Error(err))
+	}
+}
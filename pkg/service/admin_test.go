@@ -0,0 +1,155 @@
+package service
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+func TestParseAdminTokens(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    AdminTokens
+		wantErr bool
+	}{
+		{
+			name: "Ingen tokens",
+			spec: "",
+			want: AdminTokens{},
+		},
+		{
+			name: "To tokens med mellomrom",
+			spec: "ola:hemmelig, kari:enda-mer-hemmelig",
+			want: AdminTokens{"ola": "hemmelig", "kari": "enda-mer-hemmelig"},
+		},
+		{
+			name:    "Mangler token",
+			spec:    "ola",
+			wantErr: true,
+		},
+		{
+			name:    "Tomt token",
+			spec:    "ola:",
+			wantErr: true,
+		},
+		{
+			name:    "Samme navn to ganger",
+			spec:    "ola:hemmelig,ola:annet",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAdminTokens(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAdminTokens() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ParseAdminTokens() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	handler := Handler{
+		AdminTokens: AdminTokens{"ola": "hemmelig"},
+		Log:         zap.NewNop(),
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantActor     string
+	}{
+		{
+			name:          "Gyldig token",
+			authorization: "Bearer hemmelig",
+			wantStatus:    http.StatusOK,
+			wantActor:     "admin:ola",
+		},
+		{
+			name:          "Feil token",
+			authorization: "Bearer gjettet",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "Mangler Bearer",
+			authorization: "hemmelig",
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:       "Mangler Authorization",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var actor string
+			next := handler.requireAdmin(func(w http.ResponseWriter, r *http.Request, a string) {
+				actor = a
+			})
+
+			r := httptest.NewRequest(http.MethodGet, "/admin/plans", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			next(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("requireAdmin() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+			if actor != tt.wantActor {
+				t.Errorf("requireAdmin() actor = %v, want %v", actor, tt.wantActor)
+			}
+		})
+	}
+}
+
+func TestRegisterAdmin(t *testing.T) {
+	mux := http.NewServeMux()
+	Handler{}.RegisterAdmin(mux)
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/plans", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, r)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("RegisterAdmin() without tokens served /admin/plans with status %v, want %v", w.Code, http.StatusNotFound)
+	}
+}
+
+func Test_toAdminPlan(t *testing.T) {
+	attemptAt := time.Date(2022, 10, 13, 8, 0, 0, 0, time.UTC)
+	row := gensql.Beredskapsvakt{
+		ID:                 uuid.MustParse("b4ac8e53-9d64-4557-8ef8-d00774ab9c06"),
+		Ident:              []byte("kryptert"),
+		PeriodBegin:        time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:          time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC),
+		LastAttemptAt:      sql.NullTime{Time: attemptAt, Valid: true},
+		LastAttemptStatus:  sql.NullString{String: attemptRejected, Valid: true},
+		LastAttemptMessage: sql.NullString{String: "Timelisten er ikke godkjent", Valid: true},
+	}
+
+	want := adminPlan{
+		ID:          row.ID,
+		PeriodBegin: "2022-10-05",
+		PeriodEnd:   "2022-10-12",
+		LastAttempt: &adminAttempt{
+			At:      attemptAt,
+			attempt: attempt{Status: attemptRejected, Message: "Timelisten er ikke godkjent"},
+		},
+	}
+	if diff := cmp.Diff(want, toAdminPlan(row), cmp.AllowUnexported(adminAttempt{})); diff != "" {
+		t.Errorf("toAdminPlan() mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+)
+
+// Hvem og hva som føres i revisjonsloggen
+const (
+	auditActorPurge            = "purge"
+	auditActionPurgePlan       = "purge_plan"
+	auditActionRetryPlan       = "retry_plan"
+	auditActionCancelPlan      = "cancel_plan"
+	auditActionRecalculatePlan = "recalculate_plan"
+	// auditActionPlanAttempt er utfallet av en beregning en operatør har startet, med handlingen i detaljene
+	auditActionPlanAttempt = "plan_attempt"
+)
+
+// audit fører en handling på en vaktperiode i revisjonsloggen
+func audit(ctx context.Context, queries *gensql.Queries, actor, action string, beredskapsvaktID uuid.UUID, details string) error {
+	return queries.CreateAuditLog(ctx, gensql.CreateAuditLogParams{
+		Actor:            actor,
+		Action:           action,
+		BeredskapsvaktID: uuid.NullUUID{UUID: beredskapsvaktID, Valid: true},
+		Details:          details,
+	})
+}
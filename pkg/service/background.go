@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"sync"
+)
+
+// Background holder styr på beregningene som startes fra endepunktene og kjører etter at de har svart, slik at de kan
+// gjøres ferdig, og vaktperiodene slippes, før tjenesten stopper.
+type Background struct {
+	// ctx stoppes først når Drain gir opp, slik at beregningene kan lagre utfallet mens tjenesten stopper
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBackground lager en Background uten beregninger
+func NewBackground() *Background {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Background{ctx: ctx, cancel: cancel}
+}
+
+// inBackground kjører f i bakgrunnen, med en handler som bruker konteksten til Background
+func (h Handler) inBackground(f func(handler Handler)) {
+	handler := h
+	handler.Context = h.Background.ctx
+
+	h.Background.wg.Add(1)
+	go func() {
+		defer h.Background.wg.Done()
+		f(handler)
+	}()
+}
+
+// Drain venter på beregningene i bakgrunnen. Er de ikke ferdige før ctx er ferdig, stoppes de, og vaktperiodene de
+// har tatt kan beregnes igjen når claimLease er ute.
+func (b *Background) Drain(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
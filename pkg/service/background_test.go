@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackgroundDrain(t *testing.T) {
+	handler := Handler{Context: context.Background(), Background: NewBackground()}
+
+	// En beregning som er ferdig blir ventet på
+	finished := make(chan struct{})
+	handler.inBackground(func(Handler) {
+		<-finished
+	})
+	close(finished)
+	if err := handler.Background.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() returned an error: %v", err)
+	}
+
+	// En beregning som ikke blir ferdig stoppes når Drain gir opp
+	stopped := make(chan error, 1)
+	handler.inBackground(func(handler Handler) {
+		<-handler.Context.Done()
+		stopped <- handler.Context.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := handler.Background.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Drain() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := <-stopped; !errors.Is(err, context.Canceled) {
+		t.Errorf("context of the calculation = %v, want %v", err, context.Canceled)
+	}
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+// captureMinWinTid tar vare på responsen fra MinWinTid, kryptert, slik at beregningen kan gjenskapes senere. Det er
+// ikke en feil i beregningen om responsen ikke blir lagret, så det blir kun logget.
+func captureMinWinTid(handler Handler, beredskapsvaktID uuid.UUID, body []byte) {
+	dataKey, err := handler.Keyring.NewDataKey(boundTo(beredskapsvaktID, "minwintid_capture.data_key"))
+	if err != nil {
+		handler.Log.Error("Failed while creating data key for response from MinWinTid", zap.Error(err), zap.String(vaktplanId, beredskapsvaktID.String()))
+		return
+	}
+
+	sealed, err := dataKey.Seal(body, boundTo(beredskapsvaktID, "minwintid_capture.response"))
+	if err != nil {
+		handler.Log.Error("Failed while encrypting response from MinWinTid", zap.Error(err), zap.String(vaktplanId, beredskapsvaktID.String()))
+		return
+	}
+
+	err = handler.Queries.CreateMinWinTidCapture(handler.Context, gensql.CreateMinWinTidCaptureParams{
+		BeredskapsvaktID: beredskapsvaktID,
+		Response:         sealed,
+		KeyID:            dataKey.KeyID,
+		DataKey:          dataKey.Wrapped,
+	})
+	if err != nil {
+		handler.Log.Error("Failed while storing response from MinWinTid", zap.Error(err), zap.String(vaktplanId, beredskapsvaktID.String()))
+	}
+}
+
+// replayMinWinTid returnerer den siste responsen fra MinWinTid som er tatt vare på for en vaktperiode
+func replayMinWinTid(handler Handler, beredskapsvaktID uuid.UUID) (models.MWTRespons, error) {
+	capture, err := handler.Queries.GetLatestMinWinTidCapture(handler.Context, beredskapsvaktID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.MWTRespons{}, fmt.Errorf("there is no captured response from MinWinTid for %v", beredskapsvaktID)
+		}
+		return models.MWTRespons{}, err
+	}
+
+	dataKey, err := handler.Keyring.OpenDataKey(capture.KeyID, capture.DataKey, boundTo(capture.BeredskapsvaktID, "minwintid_capture.data_key"))
+	if err != nil {
+		return models.MWTRespons{}, fmt.Errorf("opening data key for captured response from MinWinTid: %w", err)
+	}
+
+	body, err := dataKey.Open(capture.Response, boundTo(capture.BeredskapsvaktID, "minwintid_capture.response"))
+	if err != nil {
+		return models.MWTRespons{}, fmt.Errorf("decrypting captured response from MinWinTid: %w", err)
+	}
+
+	handler.Log.Info("Replaying response from MinWinTid", zap.String(vaktplanId, beredskapsvaktID.String()), zap.Time("capturedAt", capture.CapturedAt))
+	return DecodeMinWinTid(bytes.NewReader(body))
+}
+
+// purgeMinWinTidCaptures sletter responsene fra MinWinTid som er eldre enn det de skal tas vare på
+func purgeMinWinTidCaptures(handler Handler) error {
+	return handler.Queries.DeleteMinWinTidCapturesBefore(handler.Context, time.Now().Add(-handler.MinWinTidConfig.CaptureRetention))
+}
@@ -8,6 +8,10 @@ import (
 	"time"
 
 	"github.com/navikt/vaktor-lonn/pkg/auth"
+	"github.com/navikt/vaktor-lonn/pkg/calculator"
+	"github.com/navikt/vaktor-lonn/pkg/encryption"
+	"github.com/navikt/vaktor-lonn/pkg/resilience"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
 	"go.uber.org/zap"
 
@@ -18,6 +22,30 @@ type MinWinTidConfig struct {
 	BearerClient   auth.BasicAuthClient
 	Endpoint       string
 	TickerInterval time.Duration
+	// Capture tar vare på hver respons fra MinWinTid, kryptert, i CaptureRetention
+	Capture          bool
+	CaptureRetention time.Duration
+	// Replay bruker den siste responsen som er tatt vare på, i stedet for å spørre MinWinTid
+	Replay bool
+	// WebhookToken er tokenet MinWinTid bruker når den varsler om godkjente timelister
+	WebhookToken string
+	// BatchEndpoint henter timelistene til flere identer i én request, i stedet for én request per ident
+	BatchEndpoint string
+	// CacheTTL er hvor lenge en timeliste fra MinWinTid brukes for andre vaktperioder for samme ident
+	CacheTTL time.Duration
+	// Client brukes mot MinWinTid, mens Handler.Client brukes mot Vaktor Plan
+	Client http.Client
+}
+
+// DownstreamConfig sier når kallene mot MinWinTid, Vaktor Plan og token-endepunktene skal stoppes eller begrenses
+type DownstreamConfig struct {
+	// BreakerThreshold er hvor mange feil på rad som åpner breakeren for en tjeneste. Er den 0, brukes ingen breaker.
+	BreakerThreshold int
+	// BreakerCooldown er hvor lenge breakeren er åpen før et nytt kall slippes gjennom
+	BreakerCooldown time.Duration
+	// MinWinTidRateLimit og VaktorPlanRateLimit er hvor mange kall i sekundet som gjøres. Er den 0, er det ingen grense.
+	MinWinTidRateLimit  float64
+	VaktorPlanRateLimit float64
 }
 
 type Handler struct {
@@ -29,10 +57,21 @@ type Handler struct {
 	VaktorPlanEndpoint string
 	Queries            *gensql.Queries
 	Log                *zap.Logger
+	Rules              rules.Versions
+	PayrollOptions     calculator.Options
+	// Keyring krypterer vaktplanene og responsene fra MinWinTid som lagres i databasen
+	Keyring encryption.Keyring
+	Purge   PurgeConfig
+	// AdminTokens gir operatørene tilgang til admin-endepunktene
+	AdminTokens AdminTokens
+	// Breakers er breakerne for tjenestene Vaktor Lønn kaller
+	Breakers []*resilience.Breaker
+	// Background er beregningene endepunktene starter etter at de har svart
+	Background *Background
 }
 
 func NewHandler(logger *zap.Logger, dbString,
-	azureClientId, azureClientSecret, azureOpenIdTokenEndpoint, vaktorPlanEndpoint string, minWinTidConfig MinWinTidConfig,
+	azureClientId, azureClientSecret, azureOpenIdTokenEndpoint, vaktorPlanEndpoint string, minWinTidConfig MinWinTidConfig, ruleVersions rules.Versions, payrollOptions calculator.Options, keyring encryption.Keyring, purgeConfig PurgeConfig, adminTokens AdminTokens, downstreamConfig DownstreamConfig,
 ) (Handler, error) {
 	db, err := openDB(logger, dbString)
 	if err != nil {
@@ -49,7 +88,27 @@ func NewHandler(logger *zap.Logger, dbString,
 		VaktorPlanEndpoint: vaktorPlanEndpoint,
 		Queries:            gensql.New(db),
 		Log:                logger,
+		Rules:              ruleVersions,
+		PayrollOptions:     payrollOptions,
+		Keyring:            keyring,
+		Purge:              purgeConfig,
+		AdminTokens:        adminTokens,
+		Background:         NewBackground(),
+	}
+
+	handler.MinWinTidConfig.Client = http.Client{
+		Timeout: 10 * time.Second,
+	}
+
+	breaker := func(name string) *resilience.Breaker {
+		b := resilience.NewBreaker(name, downstreamConfig.BreakerThreshold, downstreamConfig.BreakerCooldown)
+		handler.Breakers = append(handler.Breakers, b)
+		return b
 	}
+	resilience.Wrap(&handler.MinWinTidConfig.Client, breaker("MinWinTid"), resilience.NewLimiter(downstreamConfig.MinWinTidRateLimit, 1))
+	resilience.Wrap(&handler.Client, breaker("Vaktor Plan"), resilience.NewLimiter(downstreamConfig.VaktorPlanRateLimit, 1))
+	resilience.Wrap(handler.MinWinTidConfig.BearerClient.Client, breaker("MinWinTid token"), nil)
+	resilience.Wrap(handler.BearerClient.Client, breaker("Azure token"), nil)
 
 	return handler, nil
 }
@@ -77,3 +136,13 @@ func openDB(logger *zap.Logger, dbString string) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// openBreaker returnerer breakeren for en tjeneste som er nede, eller nil hvis alle er oppe
+func (h Handler) openBreaker() *resilience.Breaker {
+	for _, breaker := range h.Breakers {
+		if breaker.Open() {
+			return breaker
+		}
+	}
+	return nil
+}
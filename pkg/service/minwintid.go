@@ -2,89 +2,56 @@ package service
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"sort"
-	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/navikt/vaktor-lonn/pkg/calculator"
 	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/resilience"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
 	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
-	"github.com/shopspring/decimal"
+	"github.com/navikt/vaktor-lonn/pkg/timesheet"
 	"go.uber.org/zap"
 )
 
 const (
-	DateTimeFormat  = "2006-01-02T15:04:05"
 	fravarKodeFerie = 210
 	vaktplanId      = "vaktplanId"
 )
 
-func getTimesheetFromMinWinTid(ident string, periodBegin time.Time, periodEnd time.Time, handler Handler) (models.MWTRespons, error) {
-	config := handler.MinWinTidConfig
-
-	bearerToken, err := config.BearerClient.GenerateBearerToken()
-	if err != nil {
-		return models.MWTRespons{}, fmt.Errorf("generating bearer token: %v", err)
+// DecodeMinWinTid leser en respons fra MinWinTid, og sorterer dagene etter dato. Dagene kan også være en
+// JSON-streng, slik MWTmock returnerer dem.
+func DecodeMinWinTid(r io.Reader) (models.MWTRespons, error) {
+	var raw struct {
+		models.MWTRespons
+		Dager json.RawMessage `json:"dager"`
 	}
-
-	req, err := http.NewRequest(http.MethodGet, config.Endpoint, nil)
-	if err != nil {
-		return models.MWTRespons{}, err
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return models.MWTRespons{}, fmt.Errorf("decoding MinWinTid response: %w", err)
 	}
 
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", bearerToken))
-	values := req.URL.Query()
-	values.Add("nav_id", ident)
-	values.Add("fra_dato", periodBegin.Format("2006-01-02"))
-	values.Add("til_dato", periodEnd.Format("2006-01-02"))
-	req.URL.RawQuery = values.Encode()
-
-	backoffSchedule := []time.Duration{
-		1 * time.Second,
-		3 * time.Second,
-		10 * time.Second,
+	dager := raw.Dager
+	var quoted string
+	if err := json.Unmarshal(dager, &quoted); err == nil {
+		dager = json.RawMessage(quoted)
 	}
 
-	resp, err := handler.Client.Do(req)
-	if err != nil {
-		for _, duration := range backoffSchedule {
-			handler.Log.Info("Problem connecting to MinWinTid", zap.Error(err))
-			time.Sleep(duration)
-			resp, err = handler.Client.Do(req)
-			if err == nil {
-				break
-			}
-		}
-
-		if err != nil {
-			return models.MWTRespons{}, fmt.Errorf("failed three times to connect to MinWinTid: %v", err)
-		}
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return models.MWTRespons{}, err
+	response := raw.MWTRespons
+	if len(dager) > 0 {
+		if err := json.Unmarshal(dager, &response.Dager); err != nil {
+			return models.MWTRespons{}, fmt.Errorf("decoding dager from MinWinTid: %w", err)
 		}
-
-		return models.MWTRespons{}, fmt.Errorf("minWinTid returned http(%v): %v", resp.StatusCode, string(body))
-	}
-
-	var response models.MWTRespons
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return models.MWTRespons{}, fmt.Errorf("decoding MinWinTid response: %w", err)
 	}
 
-	dager := response.Dager
-	sort.SliceStable(dager, func(i, j int) bool {
-		return dager[i].Dato < dager[j].Dato
+	sort.SliceStable(response.Dager, func(i, j int) bool {
+		return response.Dager[i].Dato < response.Dager[j].Dato
 	})
-	response.Dager = dager
 
 	return response, nil
 }
@@ -104,7 +71,7 @@ func isThereRegisteredVacationAtTheSameTimeAsGuardDuty(days []models.MWTDag, vak
 		for _, stempling := range day.Stemplinger {
 			// Denne tar ikke høyde for planlagt ferie over lengre tid
 			if stempling.Fravarkode == fravarKodeFerie {
-				date, err := time.Parse(DateTimeFormat, stempling.StemplingTid)
+				date, err := time.Parse(timesheet.DateTimeFormat, stempling.StemplingTid)
 				if err != nil {
 					return false, err
 				}
@@ -118,225 +85,7 @@ func isThereRegisteredVacationAtTheSameTimeAsGuardDuty(days []models.MWTDag, vak
 	return false, nil
 }
 
-func createClocking(innTid, utTid string) (models.Clocking, error) {
-	innStemplingDate, err := time.Parse(DateTimeFormat, innTid)
-	if err != nil {
-		return models.Clocking{}, err
-	}
-
-	utStemplingDate, err := time.Parse(DateTimeFormat, utTid)
-	if err != nil {
-		return models.Clocking{}, err
-	}
-
-	return models.Clocking{In: innStemplingDate, Out: utStemplingDate}, nil
-}
-
-func formatTimesheet(days []models.MWTDag) (map[string]models.TimeSheet, []zap.Field) {
-	timesheet := make(map[string]models.TimeSheet)
-	var nextDay []models.Clocking
-
-	for _, day := range days {
-		stemplingDate, err := time.Parse(DateTimeFormat, day.Dato)
-		if err != nil {
-			return nil, []zap.Field{zap.Error(err)}
-		}
-		simpleStemplingDate := stemplingDate.Format(calculator.VaktorDateFormat)
-		stilling := day.Stillinger[0]
-
-		ts := models.TimeSheet{
-			Date:          stemplingDate,
-			WorkingHours:  day.SkjemaTid,
-			WorkingDay:    day.Virkedag,
-			FormName:      day.SkjemaNavn,
-			Salary:        decimal.NewFromInt(int64(stilling.RATEK001)),
-			Stillingskode: stilling.Stillingskode,
-			Clockings:     []models.Clocking{},
-		}
-
-		if len(nextDay) != 0 {
-			ts.Clockings = append(ts.Clockings, nextDay...)
-			nextDay = []models.Clocking{}
-		}
-
-		stemplinger := day.Stemplinger
-		if len(stemplinger) == 0 && day.SkjemaTid != 0 {
-			ts.Clockings = append(ts.Clockings, createPerfectClocking(day.SkjemaTid, stemplingDate))
-		}
-
-		if len(stemplinger) == 1 {
-			return nil, []zap.Field{
-				zap.Error(fmt.Errorf("there are too few clockings")),
-				zap.Any("stemplinger", day.Stemplinger),
-			}
-		}
-
-		sort.SliceStable(stemplinger, func(i, j int) bool {
-			return stemplinger[i].StemplingTid < stemplinger[j].StemplingTid
-		})
-
-		for len(stemplinger) >= 2 {
-			innStempling := stemplinger[0]
-			stemplinger = stemplinger[1:]
-
-			utStempling := stemplinger[0]
-			stemplinger = stemplinger[1:]
-
-			if innStempling.Retning == "Inn" && innStempling.Type == "B1" {
-				// Dette er en vanlig stempling
-				if utStempling.Retning == "Ut" && utStempling.Type == "B2" {
-					clocking, err := createClocking(innStempling.StemplingTid, utStempling.StemplingTid)
-					if err != nil {
-						return nil, []zap.Field{zap.Error(err)}
-					}
-
-					ts.Clockings = append(ts.Clockings, clocking)
-					continue
-				}
-
-				// Dette er en stempling med overtid
-				if utStempling.Retning == "Overtid" && utStempling.Type == "B6" {
-					nesteStempling := utStempling
-					var overtimeBecauseOfGuardDuty bool
-					// Man kan ha flere overtidsstemplinger etter hverandre, så vi må sjekke om minst en av dem er BV
-					for nesteStempling.Retning == "Overtid" && nesteStempling.Type == "B6" {
-						if !overtimeBecauseOfGuardDuty {
-							overtimeBecauseOfGuardDuty = strings.Contains(strings.ToLower(nesteStempling.OvertidBegrunnelse), "bv")
-						}
-
-						nesteStempling = stemplinger[0]
-						stemplinger = stemplinger[1:]
-					}
-
-					utOvertid := nesteStempling
-
-					// Før 1. februar 2023 så var det ikke krav om å merke overtiden sin med BV
-					if stemplingDate.Before(time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)) {
-						overtimeBecauseOfGuardDuty = true
-					}
-
-					if utOvertid.Retning == "Ut" && utOvertid.Type == "B2" {
-						innStemplingDate, err := time.Parse(DateTimeFormat, innStempling.StemplingTid)
-						if err != nil {
-							return nil, []zap.Field{zap.Error(err)}
-						}
-
-						utStemplingDate, err := time.Parse(DateTimeFormat, utOvertid.StemplingTid)
-						if err != nil {
-							return nil, []zap.Field{zap.Error(err)}
-						}
-
-						if utStemplingDate.YearDay() > innStemplingDate.YearDay() &&
-							(utStemplingDate.Hour() != 0 || utStemplingDate.Minute() != 0) {
-							// Overtid over midnatt, flytter resten av tiden til neste dag
-							truncateOut := utStemplingDate.Truncate(24 * time.Hour)
-							nextDay = append(nextDay, models.Clocking{
-								In:  truncateOut,
-								Out: utStemplingDate,
-								OtG: overtimeBecauseOfGuardDuty,
-							})
-							utStemplingDate = truncateOut
-						}
-
-						ts.Clockings = append(ts.Clockings, models.Clocking{
-							In:  innStemplingDate,
-							Out: utStemplingDate,
-							OtG: overtimeBecauseOfGuardDuty,
-						})
-						continue
-					}
-					return nil, []zap.Field{
-						zap.Error(fmt.Errorf("did not get expected overtime clock-out, got direction=%v and type=%v", utOvertid.Retning, utOvertid.Type)),
-						zap.Any("stemplinger", day.Stemplinger),
-					}
-				}
-
-				// Dette er en stempling ut på fravær
-				if utStempling.Retning == "Ut på fravær" && utStempling.Type == "B5" {
-					innDate, err := time.Parse(DateTimeFormat, innStempling.StemplingTid)
-					if err != nil {
-						return nil, []zap.Field{zap.Error(err)}
-					}
-					utDate, err := time.Parse(DateTimeFormat, utStempling.StemplingTid)
-					if err != nil {
-						return nil, []zap.Field{zap.Error(err)}
-					}
-
-					// Dette er en heldagsstempling
-					if (innDate.Hour() == 8 && innDate.Minute() == 0 && innDate.Second() == 0) &&
-						(utDate.Hour() == 8 && utDate.Minute() == 0 && utDate.Second() == 1) {
-						date, err := time.Parse(DateTimeFormat, innStempling.StemplingTid)
-						if err != nil {
-							return nil, []zap.Field{zap.Error(err)}
-						}
-
-						workdayLengthRestMinutes := int(math.Mod(ts.WorkingHours, 1) * 60)
-
-						ts.Clockings = append(ts.Clockings, models.Clocking{
-							In:  date,
-							Out: time.Date(date.Year(), date.Month(), date.Day(), 15, workdayLengthRestMinutes, 0, 0, time.UTC),
-						})
-
-						if len(stemplinger) >= 2 {
-							innFravar := stemplinger[0]
-							utFravar := stemplinger[1]
-							if innFravar.Retning == "Inn fra fravær" && innFravar.Type == "B4" &&
-								utFravar.Retning == "Ut" && utFravar.Type == "B2" {
-								stemplinger = stemplinger[2:]
-							}
-						}
-						continue
-					}
-
-					clocking, err := createClocking(innStempling.StemplingTid, utStempling.StemplingTid)
-					if err != nil {
-						return nil, []zap.Field{zap.Error(err)}
-					}
-
-					ts.Clockings = append(ts.Clockings, clocking)
-					continue
-				}
-			} else if innStempling.Retning == "Inn fra fravær" && innStempling.Type == "B4" &&
-				(utStempling.Retning == "Ut" && utStempling.Type == "B2" ||
-					utStempling.Retning == "Ut på fravær" && utStempling.Type == "B5") {
-				// Fravær i arbeidstid
-				clocking, err := createClocking(innStempling.StemplingTid, utStempling.StemplingTid)
-				if err != nil {
-					return nil, []zap.Field{zap.Error(err)}
-				}
-
-				ts.Clockings = append(ts.Clockings, clocking)
-				continue
-			}
-
-			return nil, []zap.Field{
-				zap.Error(fmt.Errorf("did not get expected direction or type, got inn{direction=%v, type=%v} and out{direction=%v, type=%v}", innStempling.Retning, innStempling.Type, utStempling.Retning, utStempling.Type)),
-				zap.Any("stemplinger", day.Stemplinger),
-			}
-		}
-
-		if len(stemplinger) != 0 {
-			return nil, []zap.Field{
-				zap.Error(fmt.Errorf("there are clockings left")),
-				zap.Any("stemplinger", day.Stemplinger),
-			}
-		}
-
-		timesheet[simpleStemplingDate] = ts
-	}
-	return timesheet, nil
-}
-
-func createPerfectClocking(tid float64, date time.Time) models.Clocking {
-	in := time.Date(date.Year(), date.Month(), date.Day(), 8, 0, 0, 0, time.UTC)
-	out := in.Add(time.Duration(tid*60) * time.Minute)
-	return models.Clocking{
-		In:  in,
-		Out: out,
-	}
-}
-
-func postError(handler Handler, beredskapsvakt gensql.Beredskapsvakt, message, bearerToken string) error {
+func postError(handler Handler, beredskapsvakt models.Beredskapsvakt, message, bearerToken string) error {
 	blob := map[string]string{
 		"error": message,
 		"ok":    "false",
@@ -395,7 +144,9 @@ func postToPlan(handler Handler, payload []byte, url, bearerToken string) error
 	return nil
 }
 
-func calculateSalary(beredskapsvakt gensql.Beredskapsvakt, tiddataResult models.MWTRespons) (*models.Payroll, string, error) {
+// CalculateSalary beregner utbetalingen for en vaktperiode fra timelisten i MinWinTid. Feiler beregningen, returneres
+// også en melding som kan vises til vakthaver i Vaktor Plan.
+func CalculateSalary(beredskapsvakt models.Beredskapsvakt, tiddataResult models.MWTRespons, ruleVersions rules.Versions, options calculator.Options) (*models.Payroll, string, error) {
 	if err := isTimesheetApproved(tiddataResult.Dager); err != nil {
 		return nil, "Timelisten din er ikke godkjent av din personalleder i MinWinTid", nil
 	}
@@ -405,6 +156,12 @@ func calculateSalary(beredskapsvakt gensql.Beredskapsvakt, tiddataResult models.
 		return nil, "Ukjent data fra Vaktor Plan", fmt.Errorf("unmarshaling beredskapsvaktperiode: %w", err)
 	}
 
+	schedule, err := calculator.NormalizeSchedule(vaktplan.Schedule)
+	if err != nil {
+		return nil, "Vaktplanen fra Vaktor Plan er ikke gyldig", fmt.Errorf("normalizing schedule: %w", err)
+	}
+	vaktplan.Schedule = schedule
+
 	vacationAtTheSameTimeAsGuardDuty, err := isThereRegisteredVacationAtTheSameTimeAsGuardDuty(tiddataResult.Dager, vaktplan)
 	if err != nil {
 		return nil, "Klarte ikke sjekke om du har hatt ferie under beredkapsvakt", fmt.Errorf("parsing date from MinWinTid: %w", err)
@@ -413,33 +170,110 @@ func calculateSalary(beredskapsvakt gensql.Beredskapsvakt, tiddataResult models.
 		return nil, "Du har hatt ferie under beredskapsvakt", fmt.Errorf("user has had guard duty during vacation")
 	}
 
-	timesheet, errFields := formatTimesheet(tiddataResult.Dager)
-	if len(errFields) != 0 {
-		return nil, "Data fra MinWinTid er ikke gyldig", fmt.Errorf("tried to create timesheet: %v", errFields)
+	days, diagnostics := timesheet.Parse(tiddataResult.Dager, timesheet.Options{Rules: ruleVersions})
+	if diagnostics.HasFatal() {
+		fatal := diagnostics.Filter(timesheet.Fatal)
+		return nil, fmt.Sprintf("Data fra MinWinTid er ikke gyldig: %v", fatal), fmt.Errorf("tried to create timesheet: %v", fatal)
 	}
 
 	minWinTid := models.MinWinTid{
 		ResourceID:   tiddataResult.NavID,
 		ApproverID:   tiddataResult.LederNavID,
 		ApproverName: tiddataResult.LederNavn,
-		Satser: models.Satser{
-			Helg:    decimal.NewFromInt(65),
-			Dag:     decimal.NewFromInt(15),
-			Natt:    decimal.NewFromInt(25),
-			Utvidet: decimal.NewFromInt(25),
-		},
-		Timesheet: timesheet,
+		Timesheet:    days,
 	}
 
-	payroll, err := calculator.GuarddutySalary(vaktplan, minWinTid)
+	payroll, err := calculator.GuarddutySalary(vaktplan, minWinTid, ruleVersions, options)
 	if err != nil {
 		return nil, "Klarte ikke å beregne utbetaling", fmt.Errorf("calculating guard duty salary: %w", err)
 	}
 
+	diagnostics = append(diagnostics, timesheet.UnmarkedOvertime(days, vaktplan.Schedule)...)
+	for _, warning := range diagnostics.Filter(timesheet.Warning) {
+		payroll.Warnings = append(payroll.Warnings, warning.String())
+	}
+
 	return &payroll, "", nil
 }
 
-func handleTransaction(handler Handler, beredskapsvakt gensql.Beredskapsvakt) {
+// Utfallet av et forsøk på å beregne en vaktperiode
+const (
+	// attemptPosted er når utbetalingen er sendt til Vaktor Plan
+	attemptPosted = "posted"
+	// attemptRejected er når beregningen ikke kunne gjøres, og Vaktor Plan har fått beskjed om hvorfor
+	attemptRejected = "rejected"
+	// attemptFailed er når noe feilet underveis, og vaktperioden prøves på nytt ved neste kjøring
+	attemptFailed = "failed"
+)
+
+type attempt struct {
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// claimLease er hvor lenge en beregning har enerett på vaktperioden. Stopper beregningen uten å slippe vaktperioden,
+// kan den beregnes igjen etter dette.
+const claimLease = 10 * time.Minute
+
+// claimPlan gir beregningen enerett på vaktperioden, slik at kjøringen, varsler fra MinWinTid og operatører ikke
+// sender den samme utbetalingen flere ganger. posted sier om vaktperioden skal være utbetalt fra før. Er den tatt av en
+// annen beregning, eller ikke i den tilstanden, returneres false.
+func claimPlan(handler Handler, id uuid.UUID, posted bool) (bool, error) {
+	rows, err := handler.Queries.ClaimPlan(handler.Context, gensql.ClaimPlanParams{
+		ID:           id,
+		ClaimedUntil: sql.NullTime{Time: time.Now().Add(claimLease), Valid: true},
+		Posted:       posted,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+// releasePlan slipper vaktperioden, slik at den kan beregnes igjen med en gang
+func releasePlan(handler Handler, id uuid.UUID) {
+	if err := handler.Queries.ReleasePlan(handler.Context, id); err != nil {
+		handler.Log.Error("Failed while releasing beredskapsvakt", zap.Error(err), zap.String(vaktplanId, id.String()))
+	}
+}
+
+// handleTransaction beregner en vaktperiode som venter på beregning, hvis ingen annen beregning har tatt den. Returnerer
+// false hvis vaktperioden ikke ble beregnet.
+func handleTransaction(handler Handler, timesheets *timesheets, beredskapsvakt models.Beredskapsvakt) (attempt, bool) {
+	claimed, err := claimPlan(handler, beredskapsvakt.ID, false)
+	if err != nil {
+		handler.Log.Error("Failed while claiming beredskapsvakt", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
+		return attempt{}, false
+	}
+	if !claimed {
+		handler.Log.Info("Beredskapsvakt is claimed by another calculation or already posted", zap.String(vaktplanId, beredskapsvakt.ID.String()))
+		return attempt{}, false
+	}
+
+	return runTransaction(handler, timesheets, beredskapsvakt, false), true
+}
+
+// runTransaction beregner en vaktperiode beregningen har tatt, sender utbetalingen til Vaktor Plan, lagrer utfallet på
+// vaktperioden og slipper den. posted sier om vaktperioden er utbetalt fra før, og beregnes på nytt.
+func runTransaction(handler Handler, timesheets *timesheets, beredskapsvakt models.Beredskapsvakt, posted bool) attempt {
+	defer releasePlan(handler, beredskapsvakt.ID)
+
+	result := processTransaction(handler, timesheets, beredskapsvakt, posted)
+
+	err := handler.Queries.UpdatePlanAttempt(handler.Context, gensql.UpdatePlanAttemptParams{
+		ID:                 beredskapsvakt.ID,
+		LastAttemptStatus:  sql.NullString{String: result.Status, Valid: true},
+		LastAttemptMessage: sql.NullString{String: result.Message, Valid: result.Message != ""},
+	})
+	if err != nil {
+		handler.Log.Error("Failed while storing attempt", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
+	}
+
+	return result
+}
+
+func processTransaction(handler Handler, timesheets *timesheets, beredskapsvakt models.Beredskapsvakt, posted bool) attempt {
 	handler.Log.Info("Handling transaction", zap.String(vaktplanId, beredskapsvakt.ID.String()))
 
 	azureBearerToken, err := handler.BearerClient.GenerateBearerToken()
@@ -447,36 +281,45 @@ func handleTransaction(handler Handler, beredskapsvakt gensql.Beredskapsvakt) {
 		handler.Log.Error("Problem generating bearer token", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
 	}
 
-	response, err := getTimesheetFromMinWinTid(beredskapsvakt.Ident, beredskapsvakt.PeriodBegin, beredskapsvakt.PeriodEnd, handler)
+	response, err := timesheets.get(beredskapsvakt)
 	if err != nil {
 		handler.Log.Error("Failed while retrieving data from MinWinTid", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
-		return
+		return attempt{Status: attemptFailed, Message: fmt.Sprintf("retrieving data from MinWinTid: %v", err)}
 	}
 
-	payroll, message, err := calculateSalary(beredskapsvakt, response)
+	payroll, message, err := CalculateSalary(beredskapsvakt, response, handler.Rules, handler.PayrollOptions)
 	if err != nil || message != "" {
+		// Vaktor Plan har allerede fått utbetalingen, og skal ikke få en feilmelding for den
+		if posted {
+			handler.Log.Info("Recalculation failed, keeping the posted payroll", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()), zap.String("message", message))
+			return attempt{Status: attemptFailed, Message: fmt.Sprintf("recalculation failed: %v", message)}
+		}
+
 		handler.Log.Info("calculateSalary feilet, sender info til Plan", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()), zap.String("message", message))
 		if err := postError(handler, beredskapsvakt, message, azureBearerToken); err != nil {
 			handler.Log.Error("Failed while posting error to Vaktor Plan", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
+			return attempt{Status: attemptFailed, Message: fmt.Sprintf("posting error to Vaktor Plan: %v", err)}
 		}
 
-		return
+		return attempt{Status: attemptRejected, Message: message}
 	}
 
 	if payroll == nil {
 		handler.Log.Info("calculateSalary returnerte nil", zap.String(vaktplanId, beredskapsvakt.ID.String()))
-		return
+		return attempt{Status: attemptFailed, Message: "calculation returned no payroll"}
 	}
 
 	if err := postPayroll(handler, *payroll, azureBearerToken); err != nil {
 		handler.Log.Error("Failed while posting to Vaktor Plan", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
-		return
+		return attempt{Status: attemptFailed, Message: fmt.Sprintf("posting payroll to Vaktor Plan: %v", err)}
 	}
 
-	if err := handler.Queries.DeletePlan(handler.Context, beredskapsvakt.ID); err != nil {
-		handler.Log.Error("Failed while deleting beredskapsvakt", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
-		return
+	// Vaktperioden tas vare på en stund etter at utbetalingen er sendt, slik at den kan beregnes på nytt
+	if err := handler.Queries.MarkPlanPosted(handler.Context, beredskapsvakt.ID); err != nil {
+		handler.Log.Error("Failed while marking beredskapsvakt as posted", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
 	}
+
+	return attempt{Status: attemptPosted}
 }
 
 func handleTransactions(handler Handler) error {
@@ -485,8 +328,28 @@ func handleTransactions(handler Handler) error {
 		return err
 	}
 
-	for _, beredskapsvakt := range beredskapsvakter {
-		handleTransaction(handler, beredskapsvakt)
+	var opened []models.Beredskapsvakt
+	for _, row := range beredskapsvakter {
+		beredskapsvakt, err := openBeredskapsvakt(handler, row)
+		if err != nil {
+			handler.Log.Error("Failed while decrypting beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+			continue
+		}
+
+		resealBeredskapsvakt(handler, row, beredskapsvakt)
+		opened = append(opened, beredskapsvakt)
+	}
+
+	// Timelisten til en ident hentes for alle vaktperiodene til identen på en gang
+	timesheets := newTimesheets(handler, opened)
+	timesheets.prefetch()
+	for i, beredskapsvakt := range opened {
+		// Vaktperiodene som er igjen prøves ved neste kjøring, i stedet for å vente på en tjeneste som er nede
+		if breaker := handler.openBreaker(); breaker != nil {
+			return fmt.Errorf("aborting with %v beredskapsvakter left: %v: %w", len(opened)-i, breaker.Name(), resilience.ErrOpen)
+		}
+
+		handleTransaction(handler, timesheets, beredskapsvakt)
 	}
 
 	return nil
@@ -497,6 +360,24 @@ func Run(handler Handler) {
 	defer ticker.Stop()
 
 	for {
+		if handler.MinWinTidConfig.CaptureRetention > 0 {
+			if err := purgeMinWinTidCaptures(handler); err != nil {
+				handler.Log.Error("Failed while purging captured responses from MinWinTid", zap.Error(err))
+			}
+		}
+
+		if handler.Purge.MaxAge > 0 {
+			if err := purgeStalePlans(handler); err != nil {
+				handler.Log.Error("Failed while purging stale beredskapsvakter", zap.Error(err))
+			}
+		}
+
+		if handler.Purge.PostedMaxAge > 0 {
+			if err := purgePostedPlans(handler); err != nil {
+				handler.Log.Error("Failed while purging posted beredskapsvakter", zap.Error(err))
+			}
+		}
+
 		err := handleTransactions(handler)
 		if err != nil {
 			handler.Log.Error("Failed while handling transactions", zap.Error(err))
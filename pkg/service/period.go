@@ -10,7 +10,6 @@ import (
 
 	"github.com/navikt/vaktor-lonn/pkg/calculator"
 	"github.com/navikt/vaktor-lonn/pkg/models"
-	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
 	"go.uber.org/zap"
 )
 
@@ -52,10 +51,22 @@ func (h Handler) Period(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	schedule, err := calculator.NormalizeSchedule(plan.Schedule)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusBadRequest)
+		h.Log.Error("Error when normalizing schedule", zap.Error(err))
+		return
+	}
+
 	var dates []string
-	for key := range plan.Schedule {
+	for key := range schedule {
 		dates = append(dates, key)
 	}
+	if len(dates) == 0 {
+		http.Error(w, "Error: schedule has no guard duty", http.StatusBadRequest)
+		h.Log.Error("Schedule has no guard duty", zap.String(vaktplanId, plan.ID.String()))
+		return
+	}
 	sort.Strings(dates)
 
 	periodBegin, err := time.Parse(calculator.VaktorDateFormat, dates[0])
@@ -72,17 +83,33 @@ func (h Handler) Period(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.Queries.CreatePlan(h.Context, gensql.CreatePlanParams{
+	beredskapsvakt := models.Beredskapsvakt{
 		ID:          plan.ID,
 		Ident:       plan.Ident,
 		Plan:        body,
 		PeriodBegin: periodBegin,
 		PeriodEnd:   periodEnd,
-	}); err != nil {
+	}
+
+	sealed, err := sealBeredskapsvakt(h, beredskapsvakt)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Error when encrypting period", zap.Error(err), zap.String(vaktplanId, plan.ID.String()))
+		return
+	}
+
+	// En vaktperiode som allerede er utbetalt erstattes, mens en som venter på beregning må ikke overskrives
+	rows, err := h.Queries.CreatePlan(h.Context, sealed)
+	if err != nil {
 		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
 		h.Log.Error("Error when trying to save period", zap.Error(err), zap.String(vaktplanId, plan.ID.String()))
 		return
 	}
+	if rows == 0 {
+		http.Error(w, fmt.Sprintf("Error: period %v is already waiting to be calculated", plan.ID), http.StatusConflict)
+		h.Log.Error("Period is already waiting to be calculated", zap.String(vaktplanId, plan.ID.String()))
+		return
+	}
 
 	h.Log.Info(fmt.Sprintf("Received period %v", plan.ID))
 	_, err = fmt.Fprint(w, "{\"message\":\"Period saved\"}\n")
@@ -91,13 +118,7 @@ func (h Handler) Period(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	beredskapsvakt := gensql.Beredskapsvakt{
-		ID:          plan.ID,
-		Ident:       plan.Ident,
-		Plan:        body,
-		PeriodBegin: periodBegin,
-		PeriodEnd:   periodEnd,
-	}
-
-	go handleTransaction(h, beredskapsvakt)
+	h.inBackground(func(handler Handler) {
+		handleTransaction(handler, newTimesheets(handler, []models.Beredskapsvakt{beredskapsvakt}), beredskapsvakt)
+	})
 }
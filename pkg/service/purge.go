@@ -0,0 +1,101 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"go.uber.org/zap"
+)
+
+// PurgeConfig sier når vaktperioder som aldri får en godkjent timeliste skal slettes
+type PurgeConfig struct {
+	// MaxAge er hvor lenge etter at vaktperioden er slutt den blir slettet. Er den 0, slettes ingenting.
+	MaxAge time.Duration
+	// DryRun logger vaktperiodene som ville blitt slettet, uten å slette dem
+	DryRun bool
+	// PostedMaxAge er hvor lenge en utbetalt vaktperiode tas vare på, slik at den kan beregnes på nytt. Er den 0,
+	// slettes ingenting.
+	PostedMaxAge time.Duration
+}
+
+// purgeMessage er meldingen Vaktor Plan får når en vaktperiode er slettet
+func purgeMessage(maxAge time.Duration) string {
+	return fmt.Sprintf("Vaktperioden er slettet fra Vaktor Lønn fordi timelisten ikke ble godkjent innen %v dager etter at vakten var slutt", int(maxAge.Hours()/24))
+}
+
+// purgeStalePlans sletter vaktperioder som er slutt for lenger siden enn PurgeConfig.MaxAge, slik at de ikke hentes
+// fra MinWinTid for alltid. Vaktor Plan får beskjed før vaktperioden slettes, og slettingen føres i revisjonsloggen.
+func purgeStalePlans(handler Handler) error {
+	config := handler.Purge
+	rows, err := handler.Queries.ListBeredskapsvakterEndedBefore(handler.Context, time.Now().Add(-config.MaxAge))
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if config.DryRun {
+		for _, row := range rows {
+			handler.Log.Info("Would purge beredskapsvakt", zap.String(vaktplanId, row.ID.String()), zap.Time("periodEnd", row.PeriodEnd))
+		}
+		return nil
+	}
+
+	bearerToken, err := handler.BearerClient.GenerateBearerToken()
+	if err != nil {
+		return fmt.Errorf("generating bearer token: %w", err)
+	}
+
+	message := purgeMessage(config.MaxAge)
+	for _, row := range rows {
+		// Vaktperioden slettes ikke før Vaktor Plan har fått beskjed, så den prøves på nytt neste gang
+		if err := postError(handler, models.Beredskapsvakt{ID: row.ID}, message, bearerToken); err != nil {
+			handler.Log.Error("Failed while notifying Vaktor Plan about purged beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+			continue
+		}
+
+		details := fmt.Sprintf("period ended %v: %v", row.PeriodEnd.Format(time.DateOnly), message)
+		if err := deleteAuditedPlan(handler, row.ID, auditActorPurge, auditActionPurgePlan, details); err != nil {
+			handler.Log.Error("Failed while purging beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+			continue
+		}
+
+		handler.Log.Info("Purged beredskapsvakt", zap.String(vaktplanId, row.ID.String()), zap.Time("periodEnd", row.PeriodEnd))
+	}
+
+	return nil
+}
+
+// deleteAuditedPlan sletter vaktperioden og fører det i revisjonsloggen i samme transaksjon
+func deleteAuditedPlan(handler Handler, id uuid.UUID, actor, action, details string) error {
+	tx, err := handler.DB.BeginTx(handler.Context, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	queries := handler.Queries.WithTx(tx)
+	if err := queries.DeletePlan(handler.Context, id); err != nil {
+		return err
+	}
+
+	if err := audit(handler.Context, queries, actor, action, id, details); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// purgePostedPlans sletter vaktperioder som ble utbetalt for lenger siden enn PurgeConfig.PostedMaxAge
+func purgePostedPlans(handler Handler) error {
+	if handler.Purge.DryRun {
+		return nil
+	}
+
+	before := sql.NullTime{Time: time.Now().Add(-handler.Purge.PostedMaxAge), Valid: true}
+	return handler.Queries.DeletePostedPlansBefore(handler.Context, before)
+}
@@ -0,0 +1,13 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_purgeMessage(t *testing.T) {
+	want := "Vaktperioden er slettet fra Vaktor Lønn fordi timelisten ikke ble godkjent innen 90 dager etter at vakten var slutt"
+	if got := purgeMessage(90 * 24 * time.Hour); got != want {
+		t.Errorf("purgeMessage() = %v, want %v", got, want)
+	}
+}
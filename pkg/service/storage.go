@@ -0,0 +1,107 @@
+package service
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+// boundTo binder en kryptert kolonne til id-en til raden den er lagret i, slik at verdien ikke kan flyttes til en
+// annen rad eller kolonne uten at dekrypteringen feiler
+func boundTo(id uuid.UUID, column string) []byte {
+	return append(id[:], column...)
+}
+
+// sealBeredskapsvakt krypterer identen og vaktplanen med en ny datanøkkel, og lager en nøklet hash av identen slik at
+// vaktperiodene kan slås opp på ident. Datanøkkelen og de krypterte verdiene er bundet til id-en til raden.
+func sealBeredskapsvakt(handler Handler, beredskapsvakt models.Beredskapsvakt) (gensql.CreatePlanParams, error) {
+	dataKey, err := handler.Keyring.NewDataKey(boundTo(beredskapsvakt.ID, "beredskapsvakt.data_key"))
+	if err != nil {
+		return gensql.CreatePlanParams{}, err
+	}
+
+	ident, err := dataKey.Seal([]byte(beredskapsvakt.Ident), boundTo(beredskapsvakt.ID, "beredskapsvakt.ident"))
+	if err != nil {
+		return gensql.CreatePlanParams{}, err
+	}
+
+	plan, err := dataKey.Seal(beredskapsvakt.Plan, boundTo(beredskapsvakt.ID, "beredskapsvakt.plan"))
+	if err != nil {
+		return gensql.CreatePlanParams{}, err
+	}
+
+	return gensql.CreatePlanParams{
+		ID:          beredskapsvakt.ID,
+		Ident:       ident,
+		Plan:        plan,
+		PeriodBegin: beredskapsvakt.PeriodBegin,
+		PeriodEnd:   beredskapsvakt.PeriodEnd,
+		IdentHash:   sql.NullString{String: handler.Keyring.Hash(beredskapsvakt.Ident), Valid: true},
+		KeyID:       sql.NullString{String: dataKey.KeyID, Valid: true},
+		DataKey:     dataKey.Wrapped,
+	}, nil
+}
+
+// openBeredskapsvakt dekrypterer en rad. Rader uten nøkkel er lagret før kryptering ble innført, og er i klartekst.
+func openBeredskapsvakt(handler Handler, row gensql.Beredskapsvakt) (models.Beredskapsvakt, error) {
+	beredskapsvakt := models.Beredskapsvakt{
+		ID:          row.ID,
+		PeriodBegin: row.PeriodBegin,
+		PeriodEnd:   row.PeriodEnd,
+	}
+
+	if !row.KeyID.Valid {
+		beredskapsvakt.Ident = string(row.Ident)
+		beredskapsvakt.Plan = row.Plan
+		return beredskapsvakt, nil
+	}
+
+	dataKey, err := handler.Keyring.OpenDataKey(row.KeyID.String, row.DataKey, boundTo(row.ID, "beredskapsvakt.data_key"))
+	if err != nil {
+		return models.Beredskapsvakt{}, err
+	}
+
+	ident, err := dataKey.Open(row.Ident, boundTo(row.ID, "beredskapsvakt.ident"))
+	if err != nil {
+		return models.Beredskapsvakt{}, fmt.Errorf("decrypting ident: %w", err)
+	}
+
+	plan, err := dataKey.Open(row.Plan, boundTo(row.ID, "beredskapsvakt.plan"))
+	if err != nil {
+		return models.Beredskapsvakt{}, fmt.Errorf("decrypting plan: %w", err)
+	}
+
+	beredskapsvakt.Ident = string(ident)
+	beredskapsvakt.Plan = plan
+	return beredskapsvakt, nil
+}
+
+// resealBeredskapsvakt krypterer en rad på nytt med den aktive nøkkelen, hvis den ikke allerede er det. Slik blir
+// rader fra før kryptering ble innført kryptert, og en nøkkel som er byttet ut kan fjernes når ingen rader bruker den.
+func resealBeredskapsvakt(handler Handler, row gensql.Beredskapsvakt, beredskapsvakt models.Beredskapsvakt) {
+	if row.KeyID.Valid && row.KeyID.String == handler.Keyring.Active() {
+		return
+	}
+
+	sealed, err := sealBeredskapsvakt(handler, beredskapsvakt)
+	if err != nil {
+		handler.Log.Error("Failed while encrypting beredskapsvakt", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
+		return
+	}
+
+	err = handler.Queries.UpdatePlanEncryption(handler.Context, gensql.UpdatePlanEncryptionParams{
+		ID:        sealed.ID,
+		Ident:     sealed.Ident,
+		Plan:      sealed.Plan,
+		IdentHash: sealed.IdentHash,
+		KeyID:     sealed.KeyID,
+		DataKey:   sealed.DataKey,
+	})
+	if err != nil {
+		handler.Log.Error("Failed while storing encrypted beredskapsvakt", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
+	}
+}
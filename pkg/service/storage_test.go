@@ -0,0 +1,107 @@
+package service
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/navikt/vaktor-lonn/pkg/encryption"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+)
+
+func testKeyring(t *testing.T, active string) encryption.Keyring {
+	t.Helper()
+
+	keys := map[string][]byte{
+		"2024": bytes.Repeat([]byte{1}, encryption.KeySize),
+		"2025": bytes.Repeat([]byte{2}, encryption.KeySize),
+	}
+	keyring, err := encryption.NewKeyring(keys, active, bytes.Repeat([]byte{9}, encryption.KeySize))
+	if err != nil {
+		t.Fatalf("NewKeyring() returned an error: %v", err)
+	}
+	return keyring
+}
+
+func TestSealAndOpenBeredskapsvakt(t *testing.T) {
+	beredskapsvakt := models.Beredskapsvakt{
+		ID:          uuid.MustParse("b4ac8e53-9d64-4557-8ef8-d00774ab9c06"),
+		Ident:       "a123456",
+		Plan:        json.RawMessage(`{"id":"b4ac8e53-9d64-4557-8ef8-d00774ab9c06","user_id":"a123456"}`),
+		PeriodBegin: time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC),
+	}
+	handler := Handler{Keyring: testKeyring(t, "2024")}
+
+	sealed, err := sealBeredskapsvakt(handler, beredskapsvakt)
+	if err != nil {
+		t.Fatalf("sealBeredskapsvakt() returned an error: %v", err)
+	}
+	if bytes.Contains(sealed.Ident, []byte(beredskapsvakt.Ident)) || bytes.Contains(sealed.Plan, []byte(beredskapsvakt.Ident)) {
+		t.Errorf("sealBeredskapsvakt() stores the ident in plaintext")
+	}
+	if sealed.IdentHash.String != handler.Keyring.Hash(beredskapsvakt.Ident) {
+		t.Errorf("sealBeredskapsvakt() ident hash = %v, want %v", sealed.IdentHash.String, handler.Keyring.Hash(beredskapsvakt.Ident))
+	}
+	if sealed.KeyID.String != "2024" {
+		t.Errorf("sealBeredskapsvakt() key id = %v, want 2024", sealed.KeyID.String)
+	}
+
+	row := gensql.Beredskapsvakt{
+		ID:          sealed.ID,
+		Ident:       sealed.Ident,
+		Plan:        sealed.Plan,
+		PeriodBegin: sealed.PeriodBegin,
+		PeriodEnd:   sealed.PeriodEnd,
+		IdentHash:   sealed.IdentHash,
+		KeyID:       sealed.KeyID,
+		DataKey:     sealed.DataKey,
+	}
+
+	// Raden kan leses etter at den aktive nøkkelen er byttet ut
+	rotated := Handler{Keyring: testKeyring(t, "2025")}
+	got, err := openBeredskapsvakt(rotated, row)
+	if err != nil {
+		t.Fatalf("openBeredskapsvakt() returned an error: %v", err)
+	}
+	if diff := cmp.Diff(beredskapsvakt, got); diff != "" {
+		t.Errorf("openBeredskapsvakt() mismatch (-want +got):\n%s", diff)
+	}
+
+	legacy := gensql.Beredskapsvakt{
+		ID:          beredskapsvakt.ID,
+		Ident:       []byte(beredskapsvakt.Ident),
+		Plan:        beredskapsvakt.Plan,
+		PeriodBegin: beredskapsvakt.PeriodBegin,
+		PeriodEnd:   beredskapsvakt.PeriodEnd,
+	}
+	got, err = openBeredskapsvakt(rotated, legacy)
+	if err != nil {
+		t.Fatalf("openBeredskapsvakt() of a row from before encryption returned an error: %v", err)
+	}
+	if diff := cmp.Diff(beredskapsvakt, got); diff != "" {
+		t.Errorf("openBeredskapsvakt() of a row from before encryption mismatch (-want +got):\n%s", diff)
+	}
+
+	// De krypterte verdiene er bundet til raden, og kan ikke flyttes til en annen vaktperiode
+	moved := row
+	moved.ID = uuid.MustParse("0c3b5d0e-6a3e-4c4e-9f5b-1a2b3c4d5e6f")
+	if _, err := openBeredskapsvakt(rotated, moved); err == nil {
+		t.Errorf("openBeredskapsvakt() of a row moved to another id returned no error")
+	}
+	swapped := row
+	swapped.Ident, swapped.Plan = row.Plan, row.Ident
+	if _, err := openBeredskapsvakt(rotated, swapped); err == nil {
+		t.Errorf("openBeredskapsvakt() with the ident and plan swapped returned no error")
+	}
+
+	row.KeyID = sql.NullString{String: "2023", Valid: true}
+	if _, err := openBeredskapsvakt(rotated, row); err == nil {
+		t.Errorf("openBeredskapsvakt() with an unknown key returned no error")
+	}
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/resilience"
+	"github.com/navikt/vaktor-lonn/pkg/timesheet"
+	"go.uber.org/zap"
+)
+
+// timesheetQuery er en ident og en periode slik MinWinTid tar imot dem
+type timesheetQuery struct {
+	Ident string `json:"nav_id"`
+	From  string `json:"fra_dato"`
+	To    string `json:"til_dato"`
+}
+
+type dateRange struct {
+	from time.Time
+	to   time.Time
+}
+
+func (r dateRange) covers(other dateRange) bool {
+	return !r.from.After(other.from) && !r.to.Before(other.to)
+}
+
+func (r dateRange) union(other dateRange) dateRange {
+	if other.from.Before(r.from) {
+		r.from = other.from
+	}
+	if other.to.After(r.to) {
+		r.to = other.to
+	}
+	return r
+}
+
+func (r dateRange) query(ident string) timesheetQuery {
+	return timesheetQuery{
+		Ident: ident,
+		From:  r.from.Format(time.DateOnly),
+		To:    r.to.Format(time.DateOnly),
+	}
+}
+
+type cachedTimesheet struct {
+	fetchedAt time.Time
+	dateRange
+	response models.MWTRespons
+	err      error
+}
+
+// timesheets henter timelistene fra MinWinTid for en kjøring. Vaktperiodene til samme ident hentes samlet for hele
+// perioden de dekker, og responsen deles opp per vaktperiode. Responsene holdes i MinWinTidConfig.CacheTTL, slik at
+// samme ident ikke hentes flere ganger i en kjøring. En timesheets skal kun brukes fra én goroutine.
+type timesheets struct {
+	handler Handler
+	ranges  map[string]dateRange
+	cache   map[string]cachedTimesheet
+}
+
+// newTimesheets lager en timesheets for vaktperiodene som skal beregnes
+func newTimesheets(handler Handler, beredskapsvakter []models.Beredskapsvakt) *timesheets {
+	t := &timesheets{
+		handler: handler,
+		ranges:  make(map[string]dateRange),
+		cache:   make(map[string]cachedTimesheet),
+	}
+
+	for _, beredskapsvakt := range beredskapsvakter {
+		period := dateRange{from: beredskapsvakt.PeriodBegin, to: beredskapsvakt.PeriodEnd}
+		if r, ok := t.ranges[beredskapsvakt.Ident]; ok {
+			period = r.union(period)
+		}
+		t.ranges[beredskapsvakt.Ident] = period
+	}
+
+	return t
+}
+
+// prefetch henter timelistene for alle identene i én request når MinWinTid har et endepunkt for det
+func (t *timesheets) prefetch() {
+	config := t.handler.MinWinTidConfig
+	if config.BatchEndpoint == "" || config.Replay || len(t.ranges) == 0 {
+		return
+	}
+
+	queries := make([]timesheetQuery, 0, len(t.ranges))
+	for ident, r := range t.ranges {
+		queries = append(queries, r.query(ident))
+	}
+
+	// Feiler requesten, eller mangler en ident i svaret, hentes timelisten for identen når vaktperiodene beregnes
+	batch, err := fetchTimesheets(t.handler, queries)
+	if err != nil {
+		t.handler.Log.Error("Failed while retrieving timesheets in batch from MinWinTid, retrieving them per ident", zap.Error(err))
+		return
+	}
+
+	fetchedAt := time.Now()
+	for ident, r := range t.ranges {
+		response, ok := batch[ident]
+		if !ok {
+			continue
+		}
+		t.cache[ident] = cachedTimesheet{fetchedAt: fetchedAt, dateRange: r, response: response}
+	}
+}
+
+// get returnerer timelisten for vaktperioden, delt ut fra timelisten for identen
+func (t *timesheets) get(beredskapsvakt models.Beredskapsvakt) (models.MWTRespons, error) {
+	period := dateRange{from: beredskapsvakt.PeriodBegin, to: beredskapsvakt.PeriodEnd}
+	if t.handler.MinWinTidConfig.Replay {
+		response, err := replayMinWinTid(t.handler, beredskapsvakt.ID)
+		if err != nil {
+			return models.MWTRespons{}, err
+		}
+		return sliceTimesheet(response, period), nil
+	}
+
+	cached, ok := t.cache[beredskapsvakt.Ident]
+	if !ok || !cached.covers(period) || time.Since(cached.fetchedAt) > t.handler.MinWinTidConfig.CacheTTL {
+		r := period
+		if planned, ok := t.ranges[beredskapsvakt.Ident]; ok {
+			r = planned.union(period)
+		}
+
+		response, err := fetchTimesheet(t.handler, r.query(beredskapsvakt.Ident))
+		cached = cachedTimesheet{fetchedAt: time.Now(), dateRange: r, response: response, err: err}
+		t.cache[beredskapsvakt.Ident] = cached
+	}
+	if cached.err != nil {
+		return models.MWTRespons{}, cached.err
+	}
+
+	// Det som tas vare på er kun dagene i vaktperioden, ikke resten av timelisten til identen
+	response := sliceTimesheet(cached.response, period)
+	if t.handler.MinWinTidConfig.Capture {
+		body, err := json.Marshal(response)
+		if err != nil {
+			t.handler.Log.Error("Failed while encoding response from MinWinTid", zap.Error(err), zap.String(vaktplanId, beredskapsvakt.ID.String()))
+		} else {
+			captureMinWinTid(t.handler, beredskapsvakt.ID, body)
+		}
+	}
+
+	return response, nil
+}
+
+// sliceTimesheet returnerer timelisten med kun dagene i perioden. Dager med en dato som ikke kan leses tas med, slik at
+// beregningen feiler på dem.
+func sliceTimesheet(response models.MWTRespons, period dateRange) models.MWTRespons {
+	dager := make([]models.MWTDag, 0, len(response.Dager))
+	for _, dag := range response.Dager {
+		date, err := time.Parse(timesheet.DateTimeFormat, dag.Dato)
+		if err == nil && (date.Before(period.from) || date.After(period.to)) {
+			continue
+		}
+		dager = append(dager, dag)
+	}
+
+	response.Dager = dager
+	return response
+}
+
+// fetchTimesheet henter timelisten for en ident fra MinWinTid
+func fetchTimesheet(handler Handler, query timesheetQuery) (models.MWTRespons, error) {
+	body, err := requestMinWinTid(handler, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, handler.MinWinTidConfig.Endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		values := req.URL.Query()
+		values.Add("nav_id", query.Ident)
+		values.Add("fra_dato", query.From)
+		values.Add("til_dato", query.To)
+		req.URL.RawQuery = values.Encode()
+		return req, nil
+	})
+	if err != nil {
+		return models.MWTRespons{}, err
+	}
+
+	response, err := DecodeMinWinTid(bytes.NewReader(body))
+	if err != nil {
+		return models.MWTRespons{}, err
+	}
+
+	return response, nil
+}
+
+// fetchTimesheets henter timelistene for flere identer i én request fra MinWinTid, og returnerer dem per ident det ble
+// spurt om
+func fetchTimesheets(handler Handler, queries []timesheetQuery) (map[string]models.MWTRespons, error) {
+	payload, err := json.Marshal(queries)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := requestMinWinTid(handler, func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPost, handler.MinWinTidConfig.BatchEndpoint, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("decoding MinWinTid batch response: %w", err)
+	}
+
+	// Timelistene kommer i samme rekkefølge som identene det ble spurt om. MinWinTid sender ikke alltid identen
+	// tilbake i nav_id.
+	if len(raw) != len(queries) {
+		return nil, fmt.Errorf("minWinTid batch response has %v timesheets for %v idents", len(raw), len(queries))
+	}
+
+	fetched := make(map[string]models.MWTRespons, len(raw))
+	for i, r := range raw {
+		response, err := DecodeMinWinTid(bytes.NewReader(r))
+		if err != nil {
+			return nil, err
+		}
+		fetched[queries[i].Ident] = response
+	}
+
+	return fetched, nil
+}
+
+// requestMinWinTid gjør en request mot MinWinTid, og prøver på nytt hvis MinWinTid ikke svarer
+func requestMinWinTid(handler Handler, newRequest func() (*http.Request, error)) ([]byte, error) {
+	bearerToken, err := handler.MinWinTidConfig.BearerClient.GenerateBearerToken()
+	if err != nil {
+		return nil, fmt.Errorf("generating bearer token: %w", err)
+	}
+
+	do := func() (*http.Response, error) {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", bearerToken))
+		return handler.MinWinTidConfig.Client.Do(req)
+	}
+
+	backoffSchedule := []time.Duration{
+		1 * time.Second,
+		3 * time.Second,
+		10 * time.Second,
+	}
+
+	resp, err := do()
+	// Er breakeren åpen, er det ingen vits i å vente og prøve på nytt
+	if err != nil && !errors.Is(err, resilience.ErrOpen) {
+		for _, duration := range backoffSchedule {
+			handler.Log.Info("Problem connecting to MinWinTid", zap.Error(err))
+			time.Sleep(duration)
+			resp, err = do()
+			if err == nil || errors.Is(err, resilience.ErrOpen) {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MinWinTid: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("minWinTid returned http(%v): %v", resp.StatusCode, string(body))
+	}
+
+	return body, nil
+}
@@ -0,0 +1,273 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	"github.com/navikt/vaktor-lonn/pkg/auth"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+func TestSliceTimesheet(t *testing.T) {
+	response := models.MWTRespons{
+		NavID: "a123456",
+		Dager: []models.MWTDag{
+			{Dato: "2022-10-04T00:00:00"},
+			{Dato: "2022-10-05T00:00:00"},
+			{Dato: "2022-10-12T00:00:00"},
+			{Dato: "2022-10-13T00:00:00"},
+			{Dato: "ugyldig"},
+		},
+	}
+	period := dateRange{
+		from: time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
+		to:   time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC),
+	}
+
+	want := models.MWTRespons{
+		NavID: "a123456",
+		Dager: []models.MWTDag{
+			{Dato: "2022-10-05T00:00:00"},
+			{Dato: "2022-10-12T00:00:00"},
+			{Dato: "ugyldig"},
+		},
+	}
+	if diff := cmp.Diff(want, sliceTimesheet(response, period)); diff != "" {
+		t.Errorf("sliceTimesheet() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// fakeMinWinTid svarer med en dag per dato i perioden det spørres om, og teller requestene. Som MWTmock svarer den
+// med identen i resource_id og en fast nav_id.
+type fakeMinWinTid struct {
+	url     string
+	queries []timesheetQuery
+	batches [][]timesheetQuery
+	// failBatch får requestene for flere identer til å feile
+	failBatch bool
+}
+
+func (f *fakeMinWinTid) response(query timesheetQuery) models.MWTRespons {
+	response := models.MWTRespons{NavID: "123456", ResourceID: query.Ident}
+	from, _ := time.Parse(time.DateOnly, query.From)
+	to, _ := time.Parse(time.DateOnly, query.To)
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		response.Dager = append(response.Dager, models.MWTDag{Dato: date.Format("2006-01-02T15:04:05")})
+	}
+	return response
+}
+
+func (f *fakeMinWinTid) handler(t *testing.T) Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"access_token":"token"}`)
+	})
+	mux.HandleFunc("GET /timesheet", func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+		query := timesheetQuery{Ident: values.Get("nav_id"), From: values.Get("fra_dato"), To: values.Get("til_dato")}
+		f.queries = append(f.queries, query)
+		_ = json.NewEncoder(w).Encode(f.response(query))
+	})
+	mux.HandleFunc("POST /batch", func(w http.ResponseWriter, r *http.Request) {
+		var queries []timesheetQuery
+		if err := json.NewDecoder(r.Body).Decode(&queries); err != nil {
+			t.Errorf("decoding batch request: %v", err)
+		}
+		f.batches = append(f.batches, queries)
+		if f.failBatch {
+			http.Error(w, "utilgjengelig", http.StatusServiceUnavailable)
+			return
+		}
+
+		var responses []models.MWTRespons
+		for _, query := range queries {
+			responses = append(responses, f.response(query))
+		}
+		_ = json.NewEncoder(w).Encode(responses)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	f.url = server.URL
+
+	return Handler{
+		Client: http.Client{},
+		Log:    zap.NewNop(),
+		MinWinTidConfig: MinWinTidConfig{
+			BearerClient: auth.NewWithBasicAuth("id", "secret", server.URL+"/token"),
+			Endpoint:     server.URL + "/timesheet",
+			CacheTTL:     time.Minute,
+		},
+	}
+}
+
+func testPlan(ident string, begin, end int) models.Beredskapsvakt {
+	return models.Beredskapsvakt{
+		ID:          uuid.New(),
+		Ident:       ident,
+		PeriodBegin: time.Date(2022, 10, begin, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2022, 10, end, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTimesheets(t *testing.T) {
+	first := testPlan("a123456", 5, 12)
+	second := testPlan("a123456", 10, 19)
+	other := testPlan("b654321", 1, 3)
+	beredskapsvakter := []models.Beredskapsvakt{first, second, other}
+
+	tests := []struct {
+		name        string
+		batch       bool
+		failBatch   bool
+		wantQueries []timesheetQuery
+		wantBatches int
+	}{
+		{
+			name: "Én request per ident for hele perioden",
+			wantQueries: []timesheetQuery{
+				{Ident: "a123456", From: "2022-10-05", To: "2022-10-19"},
+				{Ident: "b654321", From: "2022-10-01", To: "2022-10-03"},
+			},
+		},
+		{
+			name:        "Én request for alle identene",
+			batch:       true,
+			wantBatches: 1,
+		},
+		{
+			name:      "Én request per ident når requesten for alle identene feiler",
+			batch:     true,
+			failBatch: true,
+			wantQueries: []timesheetQuery{
+				{Ident: "a123456", From: "2022-10-05", To: "2022-10-19"},
+				{Ident: "b654321", From: "2022-10-01", To: "2022-10-03"},
+			},
+			wantBatches: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeMinWinTid{failBatch: tt.failBatch}
+			handler := fake.handler(t)
+			if tt.batch {
+				handler.MinWinTidConfig.BatchEndpoint = fake.url + "/batch"
+			}
+
+			timesheets := newTimesheets(handler, beredskapsvakter)
+			timesheets.prefetch()
+			for _, beredskapsvakt := range beredskapsvakter {
+				response, err := timesheets.get(beredskapsvakt)
+				if err != nil {
+					t.Fatalf("get() returned an error: %v", err)
+				}
+
+				days := int(beredskapsvakt.PeriodEnd.Sub(beredskapsvakt.PeriodBegin).Hours()/24) + 1
+				if len(response.Dager) != days {
+					t.Errorf("get() returned %v days for %v, want %v", len(response.Dager), beredskapsvakt.Ident, days)
+				}
+				if response.Dager[0].Dato != beredskapsvakt.PeriodBegin.Format("2006-01-02T15:04:05") {
+					t.Errorf("get() starts at %v, want %v", response.Dager[0].Dato, beredskapsvakt.PeriodBegin)
+				}
+			}
+
+			if diff := cmp.Diff(tt.wantQueries, fake.queries); diff != "" {
+				t.Errorf("requests to MinWinTid mismatch (-want +got):\n%s", diff)
+			}
+			if len(fake.batches) != tt.wantBatches {
+				t.Errorf("got %v batch requests to MinWinTid, want %v", len(fake.batches), tt.wantBatches)
+			}
+		})
+	}
+}
+
+func TestTimesheetsRefetchesAfterCacheTTL(t *testing.T) {
+	fake := &fakeMinWinTid{}
+	handler := fake.handler(t)
+	handler.MinWinTidConfig.CacheTTL = 0
+
+	beredskapsvakt := testPlan("a123456", 5, 12)
+	timesheets := newTimesheets(handler, []models.Beredskapsvakt{beredskapsvakt})
+	for range 2 {
+		if _, err := timesheets.get(beredskapsvakt); err != nil {
+			t.Fatalf("get() returned an error: %v", err)
+		}
+	}
+
+	if len(fake.queries) != 2 {
+		t.Errorf("got %v requests to MinWinTid, want 2", len(fake.queries))
+	}
+}
+
+// fakeCaptures tar imot responsene fra MinWinTid som tas vare på, i stedet for databasen
+type fakeCaptures struct {
+	gensql.DBTX
+	captures []gensql.CreateMinWinTidCaptureParams
+}
+
+func (f *fakeCaptures) ExecContext(_ context.Context, _ string, args ...interface{}) (sql.Result, error) {
+	f.captures = append(f.captures, gensql.CreateMinWinTidCaptureParams{
+		BeredskapsvaktID: args[0].(uuid.UUID),
+		Response:         args[1].([]byte),
+		KeyID:            args[2].(string),
+		DataKey:          args[3].([]byte),
+	})
+	return nil, nil
+}
+
+func TestTimesheetsCapturesOnlyThePeriod(t *testing.T) {
+	fake := &fakeMinWinTid{}
+	handler := fake.handler(t)
+	handler.MinWinTidConfig.Capture = true
+	handler.Keyring = testKeyring(t, "2024")
+	db := &fakeCaptures{}
+	handler.Queries = gensql.New(db)
+
+	beredskapsvakter := []models.Beredskapsvakt{
+		testPlan("a123456", 5, 12),
+		testPlan("a123456", 15, 19),
+	}
+	timesheets := newTimesheets(handler, beredskapsvakter)
+	for _, beredskapsvakt := range beredskapsvakter {
+		if _, err := timesheets.get(beredskapsvakt); err != nil {
+			t.Fatalf("get() returned an error: %v", err)
+		}
+	}
+
+	if len(db.captures) != len(beredskapsvakter) {
+		t.Fatalf("got %v captured responses, want %v", len(db.captures), len(beredskapsvakter))
+	}
+	for i, capture := range db.captures {
+		beredskapsvakt := beredskapsvakter[i]
+		dataKey, err := handler.Keyring.OpenDataKey(capture.KeyID, capture.DataKey, boundTo(capture.BeredskapsvaktID, "minwintid_capture.data_key"))
+		if err != nil {
+			t.Fatalf("OpenDataKey() returned an error: %v", err)
+		}
+		body, err := dataKey.Open(capture.Response, boundTo(capture.BeredskapsvaktID, "minwintid_capture.response"))
+		if err != nil {
+			t.Fatalf("Open() returned an error: %v", err)
+		}
+		response, err := DecodeMinWinTid(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("DecodeMinWinTid() returned an error: %v", err)
+		}
+
+		// Dagene til den andre vaktperioden til identen skal ikke tas vare på
+		period := dateRange{from: beredskapsvakt.PeriodBegin, to: beredskapsvakt.PeriodEnd}
+		want := fake.response(period.query(beredskapsvakt.Ident))
+		if diff := cmp.Diff(want, response); diff != "" {
+			t.Errorf("captured response for %v mismatch (-want +got):\n%s", beredskapsvakt.ID, diff)
+		}
+	}
+}
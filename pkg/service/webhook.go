@@ -0,0 +1,108 @@
+package service
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+// RegisterWebhook legger til endepunktet MinWinTid varsler godkjente timelister på. Uten
+// MinWinTidConfig.WebhookToken legges det ikke til, og vaktperiodene hentes kun ved hver kjøring.
+func (h Handler) RegisterWebhook(mux *http.ServeMux) {
+	if h.MinWinTidConfig.WebhookToken == "" {
+		return
+	}
+
+	mux.HandleFunc("POST /minwintid/approved", h.Approved)
+}
+
+// Approved beregner vaktperiodene som venter på beregning for identen, og som overlapper med perioden som er
+// godkjent, med en gang i stedet for ved neste kjøring
+func (h Handler) Approved(w http.ResponseWriter, r *http.Request) {
+	token, ok := requestBearerToken(r)
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.MinWinTidConfig.WebhookToken)) != 1 {
+		http.Error(w, "Error: unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	// Varselet har identen og perioden som er godkjent, på samme form som MinWinTid tar imot dem
+	var body timesheetQuery
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusBadRequest)
+		h.Log.Error("Error when decoding approval from MinWinTid", zap.Error(err))
+		return
+	}
+	if body.Ident == "" {
+		http.Error(w, "Error: nav_id is required", http.StatusBadRequest)
+		return
+	}
+
+	from, err := time.Parse(time.DateOnly, body.From)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: fra_dato: %s", err), http.StatusBadRequest)
+		return
+	}
+	to, err := time.Parse(time.DateOnly, body.To)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: til_dato: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := h.Queries.ListBeredskapsvakterByIdentHash(r.Context(), sql.NullString{String: h.Keyring.Hash(body.Ident), Valid: true})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Error: %s", err), http.StatusInternalServerError)
+		h.Log.Error("Error when listing beredskapsvakter for approval", zap.Error(err))
+		return
+	}
+
+	approved := approvedPlans(rows, from, to)
+	h.Log.Info("Received approval from MinWinTid", zap.Int("plans", len(approved)))
+
+	w.WriteHeader(http.StatusAccepted)
+	if _, err := fmt.Fprintf(w, "{\"plans\":%d}\n", len(approved)); err != nil {
+		h.Log.Error("Error when returning success", zap.Error(err))
+	}
+
+	if len(approved) > 0 {
+		h.inBackground(func(handler Handler) {
+			handleApprovedPlans(handler, approved)
+		})
+	}
+}
+
+// approvedPlans returnerer vaktperiodene som overlapper med perioden fra og med from til og med to
+func approvedPlans(rows []gensql.Beredskapsvakt, from, to time.Time) []gensql.Beredskapsvakt {
+	var approved []gensql.Beredskapsvakt
+	for _, row := range rows {
+		if row.PeriodBegin.After(to) || row.PeriodEnd.Before(from) {
+			continue
+		}
+		approved = append(approved, row)
+	}
+	return approved
+}
+
+func handleApprovedPlans(handler Handler, rows []gensql.Beredskapsvakt) {
+	var opened []models.Beredskapsvakt
+	for _, row := range rows {
+		beredskapsvakt, err := openBeredskapsvakt(handler, row)
+		if err != nil {
+			handler.Log.Error("Failed while decrypting beredskapsvakt", zap.Error(err), zap.String(vaktplanId, row.ID.String()))
+			continue
+		}
+		opened = append(opened, beredskapsvakt)
+	}
+
+	// Alle vaktperiodene er for samme ident, så timelisten hentes én gang
+	timesheets := newTimesheets(handler, opened)
+	for _, beredskapsvakt := range opened {
+		handleTransaction(handler, timesheets, beredskapsvakt)
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/uuid"
+	gensql "github.com/navikt/vaktor-lonn/pkg/sql/gen"
+	"go.uber.org/zap"
+)
+
+func Test_approvedPlans(t *testing.T) {
+	date := func(day int) time.Time {
+		return time.Date(2022, 10, day, 0, 0, 0, 0, time.UTC)
+	}
+	plan := func(id string, begin, end int) gensql.Beredskapsvakt {
+		return gensql.Beredskapsvakt{ID: uuid.MustParse(id), PeriodBegin: date(begin), PeriodEnd: date(end)}
+	}
+	before := plan("00000000-0000-0000-0000-000000000001", 1, 4)
+	overlapsBegin := plan("00000000-0000-0000-0000-000000000002", 3, 6)
+	inside := plan("00000000-0000-0000-0000-000000000003", 6, 8)
+	endsOnFirstDay := plan("00000000-0000-0000-0000-000000000004", 1, 5)
+	after := plan("00000000-0000-0000-0000-000000000005", 13, 20)
+	rows := []gensql.Beredskapsvakt{before, overlapsBegin, inside, endsOnFirstDay, after}
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want []gensql.Beredskapsvakt
+	}{
+		{
+			name: "Godkjent uke",
+			from: date(5),
+			to:   date(12),
+			want: []gensql.Beredskapsvakt{overlapsBegin, inside, endsOnFirstDay},
+		},
+		{
+			name: "Godkjent før alle vaktperiodene",
+			from: date(1),
+			to:   date(1),
+			want: []gensql.Beredskapsvakt{before, endsOnFirstDay},
+		},
+		{
+			name: "Ingen vaktperioder i perioden",
+			from: date(21),
+			to:   date(28),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := approvedPlans(rows, tt.from, tt.to)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("approvedPlans() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestApproved(t *testing.T) {
+	handler := Handler{
+		MinWinTidConfig: MinWinTidConfig{WebhookToken: "hemmelig"},
+		Log:             zap.NewNop(),
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		body          string
+		wantStatus    int
+	}{
+		{
+			name:          "Feil token",
+			authorization: "Bearer gjettet",
+			body:          `{"nav_id":"a123456","fra_dato":"2022-10-05","til_dato":"2022-10-12"}`,
+			wantStatus:    http.StatusUnauthorized,
+		},
+		{
+			name:          "Mangler ident",
+			authorization: "Bearer hemmelig",
+			body:          `{"fra_dato":"2022-10-05","til_dato":"2022-10-12"}`,
+			wantStatus:    http.StatusBadRequest,
+		},
+		{
+			name:          "Ugyldig dato",
+			authorization: "Bearer hemmelig",
+			body:          `{"nav_id":"a123456","fra_dato":"05.10.2022","til_dato":"2022-10-12"}`,
+			wantStatus:    http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/minwintid/approved", strings.NewReader(tt.body))
+			r.Header.Set("Authorization", tt.authorization)
+			w := httptest.NewRecorder()
+			handler.Approved(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Approved() status = %v, want %v", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package skjema
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultStart er når arbeidsdagen starter for skjemaer som ikke oppgir et tidspunkt i navnet
+const defaultStart = 8 * time.Hour
+
+// timeSpan finner tidsrommet i navnet til et skjema, for eksempel 0800-1545 i "BV 0800-1545 m/Beredskapsvakt"
+var timeSpan = regexp.MustCompile(`\b([01]\d|2[0-4])([0-5]\d)-([01]\d|2[0-4])([0-5]\d)\b`)
+
+// holidayMarker er merket MinWinTid setter bak navnet på skjemaer for helligdager, for eksempel "Julaften 0800-1200 *".
+// Tidsrommet i disse navnene er kjernetiden for helligdagen, og ikke når arbeidsdagen starter.
+const holidayMarker = "*"
+
+// Skjema beskriver arbeidstidsordningen en ansatt har en gitt dag i MinWinTid.
+// Alle tidspunkter er oppgitt som varighet fra midnatt.
+type Skjema struct {
+	// Start er når en perfekt arbeidsdag starter
+	Start time.Duration
+	// Length er lengden på arbeidsdagen, hentet fra skjema_tid
+	Length time.Duration
+	// FlexBegin og FlexEnd er tidsrommet skjemaet gjelder for. De er kun satt når skjemaet oppgir et
+	// tidsrom i navnet, slik at deltidsansatte og alternative ordninger får kjernetid innenfor sin egen dag.
+	FlexBegin time.Duration
+	FlexEnd   time.Duration
+}
+
+// Parse lager et Skjema basert på navnet og lengden på arbeidsdagen fra MinWinTid
+func Parse(formName string, workingHours float64) Skjema {
+	skjema := Skjema{
+		Start:  defaultStart,
+		Length: time.Duration(math.Round(workingHours*60)) * time.Minute,
+	}
+
+	if isHoliday(formName) {
+		return skjema
+	}
+
+	match := timeSpan.FindStringSubmatch(formName)
+	if match == nil {
+		return skjema
+	}
+
+	begin := clock(match[1], match[2])
+	end := clock(match[3], match[4])
+	if end <= begin {
+		return skjema
+	}
+
+	skjema.Start = begin
+	skjema.FlexBegin = begin
+	skjema.FlexEnd = end
+
+	return skjema
+}
+
+// HasFlexBand returnerer true hvis skjemaet oppgir hvilket tidsrom det gjelder for
+func (s Skjema) HasFlexBand() bool {
+	return s.FlexEnd > s.FlexBegin
+}
+
+// Begin returnerer når arbeidsdagen starter på en gitt dato
+func (s Skjema) Begin(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Add(s.Start)
+}
+
+// End returnerer når en perfekt arbeidsdag slutter på en gitt dato
+func (s Skjema) End(date time.Time) time.Time {
+	return s.Begin(date).Add(s.Length)
+}
+
+// isHoliday returnerer true hvis skjemaet er for en helligdag
+func isHoliday(formName string) bool {
+	return strings.HasSuffix(strings.TrimSpace(formName), holidayMarker)
+}
+
+func clock(hours, minutes string) time.Duration {
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute
+}
@@ -0,0 +1,98 @@
+package skjema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	type args struct {
+		formName     string
+		workingHours float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want Skjema
+	}{
+		{
+			name: "Vanlig beredskapsvaktskjema",
+			args: args{
+				formName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+				workingHours: 7.75,
+			},
+			want: Skjema{
+				Start:     8 * time.Hour,
+				Length:    7*time.Hour + 45*time.Minute,
+				FlexBegin: 8 * time.Hour,
+				FlexEnd:   15*time.Hour + 45*time.Minute,
+			},
+		},
+		{
+			name: "Deltid på ettermiddagen",
+			args: args{
+				formName:     "Deltid 60% 1200-1630",
+				workingHours: 4.5,
+			},
+			want: Skjema{
+				Start:     12 * time.Hour,
+				Length:    4*time.Hour + 30*time.Minute,
+				FlexBegin: 12 * time.Hour,
+				FlexEnd:   16*time.Hour + 30*time.Minute,
+			},
+		},
+		{
+			name: "Skjema uten tidsrom",
+			args: args{
+				formName:     "BV Lørdag IKT",
+				workingHours: 0,
+			},
+			want: Skjema{
+				Start: 8 * time.Hour,
+			},
+		},
+		{
+			name: "Årstall er ikke et tidsrom",
+			args: args{
+				formName:     "Heltid (2018)",
+				workingHours: 7.5,
+			},
+			want: Skjema{
+				Start:  8 * time.Hour,
+				Length: 7*time.Hour + 30*time.Minute,
+			},
+		},
+		{
+			name: "Tidsrommet i skjemaet for nyttårsaften er ikke arbeidsdagen",
+			args: args{
+				formName:     "Nyttårsaften 1000-1200 *",
+				workingHours: 2,
+			},
+			want: Skjema{
+				Start:  8 * time.Hour,
+				Length: 2 * time.Hour,
+			},
+		},
+		{
+			name: "Tidsrommet i skjemaet for julaften er ikke arbeidsdagen",
+			args: args{
+				formName:     "Julaften 0800-1200 *",
+				workingHours: 4,
+			},
+			want: Skjema{
+				Start:  8 * time.Hour,
+				Length: 4 * time.Hour,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.args.formName, tt.args.workingHours)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -5,17 +5,52 @@
 package gensql
 
 import (
-	"encoding/json"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+type AuditLog struct {
+	ID        int64
+	CreatedAt time.Time
+	// A job in Vaktor Lønn, or the user of the admin API
+	Actor            string
+	Action           string
+	BeredskapsvaktID uuid.NullUUID
+	Details          string
+}
+
 type Beredskapsvakt struct {
 	// Created by Vaktor Plan
-	ID          uuid.UUID
-	Ident       string
-	Plan        json.RawMessage
+	ID uuid.UUID
+	// Encrypted with the data key, bound to the id of the row
+	Ident []byte
+	// Encrypted with the data key, bound to the id of the row
+	Plan        []byte
 	PeriodBegin time.Time
 	PeriodEnd   time.Time
+	// Keyed hash of the ident, for lookups
+	IdentHash sql.NullString
+	// Key the data key is encrypted with. Rows without a key are not encrypted yet
+	KeyID   sql.NullString
+	DataKey []byte
+	// When the payroll was posted to Vaktor Plan. Posted rows are kept for recalculation until retention
+	PostedAt           sql.NullTime
+	LastAttemptAt      sql.NullTime
+	LastAttemptStatus  sql.NullString
+	LastAttemptMessage sql.NullString
+	// The row is being calculated until then. A claim that is not released expires, so the row can be calculated again
+	ClaimedUntil sql.NullTime
+}
+
+type MinwintidCapture struct {
+	ID               int64
+	BeredskapsvaktID uuid.UUID
+	CapturedAt       time.Time
+	// Raw response from MinWinTid, encrypted with the data key, bound to the beredskapsvakt
+	Response []byte
+	// Key the data key is encrypted with
+	KeyID   string
+	DataKey []byte
 }
@@ -7,34 +7,137 @@ package gensql
 
 import (
 	"context"
-	"encoding/json"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
-const createPlan = `-- name: CreatePlan :exec
+const claimPlan = `-- name: ClaimPlan :execrows
+UPDATE beredskapsvakt
+SET claimed_until = $2
+WHERE id = $1
+  AND (claimed_until IS NULL OR claimed_until < now())
+  AND (posted_at IS NOT NULL) = $3::boolean
+`
+
+type ClaimPlanParams struct {
+	ID           uuid.UUID
+	ClaimedUntil sql.NullTime
+	Posted       bool
+}
+
+func (q *Queries) ClaimPlan(ctx context.Context, arg ClaimPlanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimPlan, arg.ID, arg.ClaimedUntil, arg.Posted)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const createAuditLog = `-- name: CreateAuditLog :exec
+INSERT INTO audit_log
+    ("actor", "action", "beredskapsvakt_id", "details")
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateAuditLogParams struct {
+	Actor            string
+	Action           string
+	BeredskapsvaktID uuid.NullUUID
+	Details          string
+}
+
+func (q *Queries) CreateAuditLog(ctx context.Context, arg CreateAuditLogParams) error {
+	_, err := q.db.ExecContext(ctx, createAuditLog,
+		arg.Actor,
+		arg.Action,
+		arg.BeredskapsvaktID,
+		arg.Details,
+	)
+	return err
+}
+
+const createMinWinTidCapture = `-- name: CreateMinWinTidCapture :exec
+INSERT INTO minwintid_capture
+    ("beredskapsvakt_id", "response", "key_id", "data_key")
+VALUES ($1, $2, $3, $4)
+`
+
+type CreateMinWinTidCaptureParams struct {
+	BeredskapsvaktID uuid.UUID
+	Response         []byte
+	KeyID            string
+	DataKey          []byte
+}
+
+func (q *Queries) CreateMinWinTidCapture(ctx context.Context, arg CreateMinWinTidCaptureParams) error {
+	_, err := q.db.ExecContext(ctx, createMinWinTidCapture,
+		arg.BeredskapsvaktID,
+		arg.Response,
+		arg.KeyID,
+		arg.DataKey,
+	)
+	return err
+}
+
+const createPlan = `-- name: CreatePlan :execrows
 INSERT INTO beredskapsvakt
-    ("id", "ident", "plan", "period_begin", "period_end")
-VALUES ($1, $2, $3, $4, $5)
+    ("id", "ident", "plan", "period_begin", "period_end", "ident_hash", "key_id", "data_key")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO UPDATE
+    SET ident                = excluded.ident,
+        plan                 = excluded.plan,
+        period_begin         = excluded.period_begin,
+        period_end           = excluded.period_end,
+        ident_hash           = excluded.ident_hash,
+        key_id               = excluded.key_id,
+        data_key             = excluded.data_key,
+        posted_at            = NULL,
+        last_attempt_at      = NULL,
+        last_attempt_status  = NULL,
+        last_attempt_message = NULL,
+        claimed_until        = NULL
+WHERE beredskapsvakt.posted_at IS NOT NULL
+  AND (beredskapsvakt.claimed_until IS NULL OR beredskapsvakt.claimed_until < now())
 `
 
 type CreatePlanParams struct {
 	ID          uuid.UUID
-	Ident       string
-	Plan        json.RawMessage
+	Ident       []byte
+	Plan        []byte
 	PeriodBegin time.Time
 	PeriodEnd   time.Time
+	IdentHash   sql.NullString
+	KeyID       sql.NullString
+	DataKey     []byte
 }
 
-func (q *Queries) CreatePlan(ctx context.Context, arg CreatePlanParams) error {
-	_, err := q.db.ExecContext(ctx, createPlan,
+func (q *Queries) CreatePlan(ctx context.Context, arg CreatePlanParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPlan,
 		arg.ID,
 		arg.Ident,
 		arg.Plan,
 		arg.PeriodBegin,
 		arg.PeriodEnd,
+		arg.IdentHash,
+		arg.KeyID,
+		arg.DataKey,
 	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteMinWinTidCapturesBefore = `-- name: DeleteMinWinTidCapturesBefore :exec
+DELETE
+FROM minwintid_capture
+WHERE captured_at < $1
+`
+
+func (q *Queries) DeleteMinWinTidCapturesBefore(ctx context.Context, capturedAt time.Time) error {
+	_, err := q.db.ExecContext(ctx, deleteMinWinTidCapturesBefore, capturedAt)
 	return err
 }
 
@@ -49,10 +152,71 @@ func (q *Queries) DeletePlan(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deletePostedPlansBefore = `-- name: DeletePostedPlansBefore :exec
+DELETE
+FROM beredskapsvakt
+WHERE posted_at < $1
+`
+
+func (q *Queries) DeletePostedPlansBefore(ctx context.Context, postedAt sql.NullTime) error {
+	_, err := q.db.ExecContext(ctx, deletePostedPlansBefore, postedAt)
+	return err
+}
+
+const getBeredskapsvakt = `-- name: GetBeredskapsvakt :one
+SELECT id, ident, plan, period_begin, period_end, ident_hash, key_id, data_key, posted_at, last_attempt_at, last_attempt_status, last_attempt_message, claimed_until
+FROM beredskapsvakt
+WHERE id = $1
+`
+
+func (q *Queries) GetBeredskapsvakt(ctx context.Context, id uuid.UUID) (Beredskapsvakt, error) {
+	row := q.db.QueryRowContext(ctx, getBeredskapsvakt, id)
+	var i Beredskapsvakt
+	err := row.Scan(
+		&i.ID,
+		&i.Ident,
+		&i.Plan,
+		&i.PeriodBegin,
+		&i.PeriodEnd,
+		&i.IdentHash,
+		&i.KeyID,
+		&i.DataKey,
+		&i.PostedAt,
+		&i.LastAttemptAt,
+		&i.LastAttemptStatus,
+		&i.LastAttemptMessage,
+		&i.ClaimedUntil,
+	)
+	return i, err
+}
+
+const getLatestMinWinTidCapture = `-- name: GetLatestMinWinTidCapture :one
+SELECT id, beredskapsvakt_id, captured_at, response, key_id, data_key
+FROM minwintid_capture
+WHERE beredskapsvakt_id = $1
+ORDER BY captured_at DESC, id DESC
+LIMIT 1
+`
+
+func (q *Queries) GetLatestMinWinTidCapture(ctx context.Context, beredskapsvaktID uuid.UUID) (MinwintidCapture, error) {
+	row := q.db.QueryRowContext(ctx, getLatestMinWinTidCapture, beredskapsvaktID)
+	var i MinwintidCapture
+	err := row.Scan(
+		&i.ID,
+		&i.BeredskapsvaktID,
+		&i.CapturedAt,
+		&i.Response,
+		&i.KeyID,
+		&i.DataKey,
+	)
+	return i, err
+}
+
 const listBeredskapsvakter = `-- name: ListBeredskapsvakter :many
-SELECT id, ident, plan, period_begin, period_end
+SELECT id, ident, plan, period_begin, period_end, ident_hash, key_id, data_key, posted_at, last_attempt_at, last_attempt_status, last_attempt_message, claimed_until
 FROM beredskapsvakt
-ORDER BY ident
+WHERE posted_at IS NULL
+ORDER BY ident_hash
 `
 
 func (q *Queries) ListBeredskapsvakter(ctx context.Context) ([]Beredskapsvakt, error) {
@@ -70,6 +234,14 @@ func (q *Queries) ListBeredskapsvakter(ctx context.Context) ([]Beredskapsvakt, e
 			&i.Plan,
 			&i.PeriodBegin,
 			&i.PeriodEnd,
+			&i.IdentHash,
+			&i.KeyID,
+			&i.DataKey,
+			&i.PostedAt,
+			&i.LastAttemptAt,
+			&i.LastAttemptStatus,
+			&i.LastAttemptMessage,
+			&i.ClaimedUntil,
 		); err != nil {
 			return nil, err
 		}
@@ -83,3 +255,165 @@ func (q *Queries) ListBeredskapsvakter(ctx context.Context) ([]Beredskapsvakt, e
 	}
 	return items, nil
 }
+
+const listBeredskapsvakterByIdentHash = `-- name: ListBeredskapsvakterByIdentHash :many
+SELECT id, ident, plan, period_begin, period_end, ident_hash, key_id, data_key, posted_at, last_attempt_at, last_attempt_status, last_attempt_message, claimed_until
+FROM beredskapsvakt
+WHERE ident_hash = $1
+  AND posted_at IS NULL
+ORDER BY period_begin
+`
+
+func (q *Queries) ListBeredskapsvakterByIdentHash(ctx context.Context, identHash sql.NullString) ([]Beredskapsvakt, error) {
+	rows, err := q.db.QueryContext(ctx, listBeredskapsvakterByIdentHash, identHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Beredskapsvakt
+	for rows.Next() {
+		var i Beredskapsvakt
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ident,
+			&i.Plan,
+			&i.PeriodBegin,
+			&i.PeriodEnd,
+			&i.IdentHash,
+			&i.KeyID,
+			&i.DataKey,
+			&i.PostedAt,
+			&i.LastAttemptAt,
+			&i.LastAttemptStatus,
+			&i.LastAttemptMessage,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBeredskapsvakterEndedBefore = `-- name: ListBeredskapsvakterEndedBefore :many
+SELECT id, ident, plan, period_begin, period_end, ident_hash, key_id, data_key, posted_at, last_attempt_at, last_attempt_status, last_attempt_message, claimed_until
+FROM beredskapsvakt
+WHERE period_end < $1
+  AND posted_at IS NULL
+ORDER BY period_end
+`
+
+func (q *Queries) ListBeredskapsvakterEndedBefore(ctx context.Context, periodEnd time.Time) ([]Beredskapsvakt, error) {
+	rows, err := q.db.QueryContext(ctx, listBeredskapsvakterEndedBefore, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Beredskapsvakt
+	for rows.Next() {
+		var i Beredskapsvakt
+		if err := rows.Scan(
+			&i.ID,
+			&i.Ident,
+			&i.Plan,
+			&i.PeriodBegin,
+			&i.PeriodEnd,
+			&i.IdentHash,
+			&i.KeyID,
+			&i.DataKey,
+			&i.PostedAt,
+			&i.LastAttemptAt,
+			&i.LastAttemptStatus,
+			&i.LastAttemptMessage,
+			&i.ClaimedUntil,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markPlanPosted = `-- name: MarkPlanPosted :exec
+UPDATE beredskapsvakt
+SET posted_at = now()
+WHERE id = $1
+`
+
+func (q *Queries) MarkPlanPosted(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markPlanPosted, id)
+	return err
+}
+
+const releasePlan = `-- name: ReleasePlan :exec
+UPDATE beredskapsvakt
+SET claimed_until = NULL
+WHERE id = $1
+`
+
+func (q *Queries) ReleasePlan(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, releasePlan, id)
+	return err
+}
+
+const updatePlanAttempt = `-- name: UpdatePlanAttempt :exec
+UPDATE beredskapsvakt
+SET last_attempt_at      = now(),
+    last_attempt_status  = $2,
+    last_attempt_message = $3
+WHERE id = $1
+`
+
+type UpdatePlanAttemptParams struct {
+	ID                 uuid.UUID
+	LastAttemptStatus  sql.NullString
+	LastAttemptMessage sql.NullString
+}
+
+func (q *Queries) UpdatePlanAttempt(ctx context.Context, arg UpdatePlanAttemptParams) error {
+	_, err := q.db.ExecContext(ctx, updatePlanAttempt, arg.ID, arg.LastAttemptStatus, arg.LastAttemptMessage)
+	return err
+}
+
+const updatePlanEncryption = `-- name: UpdatePlanEncryption :exec
+UPDATE beredskapsvakt
+SET ident      = $2,
+    plan       = $3,
+    ident_hash = $4,
+    key_id     = $5,
+    data_key   = $6
+WHERE id = $1
+`
+
+type UpdatePlanEncryptionParams struct {
+	ID        uuid.UUID
+	Ident     []byte
+	Plan      []byte
+	IdentHash sql.NullString
+	KeyID     sql.NullString
+	DataKey   []byte
+}
+
+func (q *Queries) UpdatePlanEncryption(ctx context.Context, arg UpdatePlanEncryptionParams) error {
+	_, err := q.db.ExecContext(ctx, updatePlanEncryption,
+		arg.ID,
+		arg.Ident,
+		arg.Plan,
+		arg.IdentHash,
+		arg.KeyID,
+		arg.DataKey,
+	)
+	return err
+}
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE minwintid_capture
+(
+    id                bigserial   NOT NULL,
+    beredskapsvakt_id uuid        NOT NULL,
+    captured_at       timestamptz NOT NULL DEFAULT now(),
+    response          bytea       NOT NULL,
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX minwintid_capture_beredskapsvakt_id_idx ON minwintid_capture (beredskapsvakt_id);
+
+comment on column minwintid_capture.response is 'Raw response from MinWinTid, encrypted with AES-256-GCM';
+
+-- +goose Down
+DROP TABLE minwintid_capture;
@@ -0,0 +1,45 @@
+-- +goose Up
+ALTER TABLE beredskapsvakt
+    ALTER COLUMN ident TYPE bytea USING convert_to(ident, 'UTF8'),
+    ALTER COLUMN plan TYPE bytea USING convert_to(plan::text, 'UTF8'),
+    ADD COLUMN ident_hash text,
+    ADD COLUMN key_id     text,
+    ADD COLUMN data_key   bytea;
+
+CREATE INDEX beredskapsvakt_ident_hash_idx ON beredskapsvakt (ident_hash);
+
+comment on column beredskapsvakt.ident is 'Encrypted with the data key, bound to the id of the row';
+comment on column beredskapsvakt.plan is 'Encrypted with the data key, bound to the id of the row';
+comment on column beredskapsvakt.ident_hash is 'Keyed hash of the ident, for lookups';
+comment on column beredskapsvakt.key_id is 'Key the data key is encrypted with. Rows without a key are not encrypted yet';
+
+-- Responsene ble kryptert med én felles nøkkel, og tas kun vare på en kort stund
+DELETE
+FROM minwintid_capture;
+
+ALTER TABLE minwintid_capture
+    ADD COLUMN key_id   text  NOT NULL,
+    ADD COLUMN data_key bytea NOT NULL;
+
+comment on column minwintid_capture.response is 'Raw response from MinWinTid, encrypted with the data key, bound to the beredskapsvakt';
+comment on column minwintid_capture.key_id is 'Key the data key is encrypted with';
+
+-- +goose Down
+DELETE
+FROM minwintid_capture;
+
+ALTER TABLE minwintid_capture
+    DROP COLUMN key_id,
+    DROP COLUMN data_key;
+
+-- Krypterte rader kan ikke gjøres om til klartekst i SQL, så de må sendes fra Vaktor Plan på nytt
+DELETE
+FROM beredskapsvakt
+WHERE key_id IS NOT NULL;
+
+ALTER TABLE beredskapsvakt
+    DROP COLUMN ident_hash,
+    DROP COLUMN key_id,
+    DROP COLUMN data_key,
+    ALTER COLUMN ident TYPE text USING convert_from(ident, 'UTF8'),
+    ALTER COLUMN plan TYPE json USING convert_from(plan, 'UTF8')::json;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE audit_log
+(
+    id                bigserial   NOT NULL,
+    created_at        timestamptz NOT NULL DEFAULT now(),
+    actor             text        NOT NULL,
+    action            text        NOT NULL,
+    beredskapsvakt_id uuid,
+    details           text        NOT NULL DEFAULT '',
+    PRIMARY KEY (id)
+);
+
+CREATE INDEX audit_log_beredskapsvakt_id_idx ON audit_log (beredskapsvakt_id);
+
+comment on column audit_log.actor is 'A job in Vaktor Lønn, or the user of the admin API';
+
+-- +goose Down
+DROP TABLE audit_log;
@@ -0,0 +1,19 @@
+-- +goose Up
+ALTER TABLE beredskapsvakt
+    ADD COLUMN posted_at            timestamptz,
+    ADD COLUMN last_attempt_at      timestamptz,
+    ADD COLUMN last_attempt_status  text,
+    ADD COLUMN last_attempt_message text;
+
+comment on column beredskapsvakt.posted_at is 'When the payroll was posted to Vaktor Plan. Posted rows are kept for recalculation until retention';
+
+-- +goose Down
+DELETE
+FROM beredskapsvakt
+WHERE posted_at IS NOT NULL;
+
+ALTER TABLE beredskapsvakt
+    DROP COLUMN posted_at,
+    DROP COLUMN last_attempt_at,
+    DROP COLUMN last_attempt_status,
+    DROP COLUMN last_attempt_message;
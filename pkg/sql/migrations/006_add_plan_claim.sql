@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE beredskapsvakt
+    ADD COLUMN claimed_until timestamptz;
+
+comment on column beredskapsvakt.claimed_until is 'The row is being calculated until then. A claim that is not released expires, so the row can be calculated again';
+
+-- +goose Down
+ALTER TABLE beredskapsvakt
+    DROP COLUMN claimed_until;
@@ -1,14 +1,109 @@
 -- name: ListBeredskapsvakter :many
 SELECT *
 FROM beredskapsvakt
-ORDER BY ident;
+WHERE posted_at IS NULL
+ORDER BY ident_hash;
 
--- name: CreatePlan :exec
+-- name: ListBeredskapsvakterByIdentHash :many
+SELECT *
+FROM beredskapsvakt
+WHERE ident_hash = $1
+  AND posted_at IS NULL
+ORDER BY period_begin;
+
+-- name: ListBeredskapsvakterEndedBefore :many
+SELECT *
+FROM beredskapsvakt
+WHERE period_end < $1
+  AND posted_at IS NULL
+ORDER BY period_end;
+
+-- name: GetBeredskapsvakt :one
+SELECT *
+FROM beredskapsvakt
+WHERE id = $1;
+
+-- name: CreatePlan :execrows
 INSERT INTO beredskapsvakt
-    ("id", "ident", "plan", "period_begin", "period_end")
-VALUES ($1, $2, $3, $4, $5);
+    ("id", "ident", "plan", "period_begin", "period_end", "ident_hash", "key_id", "data_key")
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (id) DO UPDATE
+    SET ident                = excluded.ident,
+        plan                 = excluded.plan,
+        period_begin         = excluded.period_begin,
+        period_end           = excluded.period_end,
+        ident_hash           = excluded.ident_hash,
+        key_id               = excluded.key_id,
+        data_key             = excluded.data_key,
+        posted_at            = NULL,
+        last_attempt_at      = NULL,
+        last_attempt_status  = NULL,
+        last_attempt_message = NULL,
+        claimed_until        = NULL
+WHERE beredskapsvakt.posted_at IS NOT NULL
+  AND (beredskapsvakt.claimed_until IS NULL OR beredskapsvakt.claimed_until < now());
+
+-- name: UpdatePlanEncryption :exec
+UPDATE beredskapsvakt
+SET ident      = $2,
+    plan       = $3,
+    ident_hash = $4,
+    key_id     = $5,
+    data_key   = $6
+WHERE id = $1;
+
+-- name: UpdatePlanAttempt :exec
+UPDATE beredskapsvakt
+SET last_attempt_at      = now(),
+    last_attempt_status  = $2,
+    last_attempt_message = $3
+WHERE id = $1;
+
+-- name: ClaimPlan :execrows
+UPDATE beredskapsvakt
+SET claimed_until = $2
+WHERE id = $1
+  AND (claimed_until IS NULL OR claimed_until < now())
+  AND (posted_at IS NOT NULL) = sqlc.arg(posted)::boolean;
+
+-- name: ReleasePlan :exec
+UPDATE beredskapsvakt
+SET claimed_until = NULL
+WHERE id = $1;
+
+-- name: MarkPlanPosted :exec
+UPDATE beredskapsvakt
+SET posted_at = now()
+WHERE id = $1;
 
 -- name: DeletePlan :exec
 DELETE
 FROM beredskapsvakt
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: DeletePostedPlansBefore :exec
+DELETE
+FROM beredskapsvakt
+WHERE posted_at < $1;
+
+-- name: CreateMinWinTidCapture :exec
+INSERT INTO minwintid_capture
+    ("beredskapsvakt_id", "response", "key_id", "data_key")
+VALUES ($1, $2, $3, $4);
+
+-- name: GetLatestMinWinTidCapture :one
+SELECT *
+FROM minwintid_capture
+WHERE beredskapsvakt_id = $1
+ORDER BY captured_at DESC, id DESC
+LIMIT 1;
+
+-- name: DeleteMinWinTidCapturesBefore :exec
+DELETE
+FROM minwintid_capture
+WHERE captured_at < $1;
+
+-- name: CreateAuditLog :exec
+INSERT INTO audit_log
+    ("actor", "action", "beredskapsvakt_id", "details")
+VALUES ($1, $2, $3, $4);
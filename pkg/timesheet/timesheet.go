@@ -0,0 +1,495 @@
+package timesheet
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/navikt/vaktor-lonn/pkg/intervals"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/rules"
+	"github.com/navikt/vaktor-lonn/pkg/skjema"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DateTimeFormat = "2006-01-02T15:04:05"
+	dateFormat     = "2006-01-02"
+)
+
+type Severity int
+
+const (
+	// Warning betyr at dagen kunne tolkes, men at vakthaver bør se over timelisten sin
+	Warning Severity = iota
+	// Fatal betyr at dagen ikke kunne tolkes, og at vi ikke kan beregne utbetaling
+	Fatal
+)
+
+func (s Severity) String() string {
+	if s == Fatal {
+		return "feil"
+	}
+	return "advarsel"
+}
+
+// Diagnostic er en melding om en dag i timelisten, skrevet slik at den kan vises til vakthaver
+type Diagnostic struct {
+	Date     string
+	Severity Severity
+	Message  string
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s (%s): %s", d.Date, d.Severity, d.Message)
+}
+
+type Diagnostics []Diagnostic
+
+// HasFatal returnerer true hvis minst en av dagene ikke kunne tolkes
+func (d Diagnostics) HasFatal() bool {
+	return slices.ContainsFunc(d, func(diagnostic Diagnostic) bool {
+		return diagnostic.Severity == Fatal
+	})
+}
+
+// Filter returnerer alle meldinger med gitt alvorlighetsgrad
+func (d Diagnostics) Filter(severity Severity) Diagnostics {
+	var filtered Diagnostics
+	for _, diagnostic := range d {
+		if diagnostic.Severity == severity {
+			filtered = append(filtered, diagnostic)
+		}
+	}
+	return filtered
+}
+
+func (d Diagnostics) String() string {
+	messages := make([]string, len(d))
+	for i, diagnostic := range d {
+		messages[i] = diagnostic.String()
+	}
+	return strings.Join(messages, "; ")
+}
+
+type direction int
+
+const (
+	directionUnknown direction = iota
+	directionIn
+	directionOut
+	directionOvertime
+)
+
+// knownTypes er stemplingstypene MinWinTid bruker (B1 inn, B2 ut, B4 inn fra fravær, B5 ut på fravær og
+// B6 overtid). Retningen til en stempling tolkes ut fra navnet, slik at også typer vi ikke har egen
+// håndtering for kan brukes.
+var knownTypes = []string{"B1", "B2", "B3", "B4", "B5", "B6"}
+
+// directions er navnene MinWinTid gir stemplingene. Navnet må være helt likt, slik at for eksempel
+// "Utrykning" ikke blir tolket som en ut-stempling.
+var directions = map[string]direction{
+	"inn":            directionIn,
+	"inn fra fravær": directionIn,
+	"ut":             directionOut,
+	"ut på fravær":   directionOut,
+	"overtid":        directionOvertime,
+}
+
+func directionOf(stempling models.MWTStempling) direction {
+	return directions[strings.ToLower(strings.TrimSpace(stempling.Retning))]
+}
+
+func directionOrder(d direction) int {
+	switch d {
+	case directionOut:
+		return 0
+	case directionIn:
+		return 1
+	}
+	return 2
+}
+
+type stamp struct {
+	models.MWTStempling
+	time      time.Time
+	direction direction
+}
+
+// Options er regler fra særavtalen som påvirker hvordan timelisten tolkes
+type Options struct {
+	// AllOvertimeIsGuardDuty betyr at all overtid regnes som utrykning, også når den ikke er merket med BV
+	AllOvertimeIsGuardDuty bool
+	// GuardDutyMarking kjenner igjen overtid som er merket med BV
+	GuardDutyMarking rules.GuardDutyMarking
+	// Rules er versjonene av reglene. Er de satt, tolkes hver dag etter versjonen som gjaldt den dagen, i stedet
+	// for etter feltene over, slik at en vaktperiode som går over i en ny versjon følger begge.
+	Rules rules.Versions
+}
+
+// at returnerer valgene som gjelder på en gitt dato
+func (o Options) at(date time.Time) (Options, error) {
+	if o.Rules == nil {
+		return o, nil
+	}
+
+	r, err := o.Rules.At(date)
+	if err != nil {
+		return Options{}, err
+	}
+
+	return Options{
+		AllOvertimeIsGuardDuty: !r.RequireGuardDutyMarking,
+		GuardDutyMarking:       r.GuardDutyMarking,
+	}, nil
+}
+
+type dayParser struct {
+	date        string
+	options     Options
+	diagnostics Diagnostics
+}
+
+func (p *dayParser) warn(format string, a ...any) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{Date: p.date, Severity: Warning, Message: fmt.Sprintf(format, a...)})
+}
+
+func (p *dayParser) fatal(format string, a ...any) {
+	p.diagnostics = append(p.diagnostics, Diagnostic{Date: p.date, Severity: Fatal, Message: fmt.Sprintf(format, a...)})
+}
+
+// Parse gjør om dagene fra MinWinTid til en timeliste per dato. Parse feiler aldri, men returnerer
+// meldinger for alt som ikke lot seg tolke. Dager med en Fatal melding er ikke med i timelisten.
+func Parse(days []models.MWTDag, options Options) (map[string]models.TimeSheet, Diagnostics) {
+	timesheet := make(map[string]models.TimeSheet)
+	// overflow er stemplinger som har gått over midnatt, og som hører til en senere dag
+	overflow := make(map[string][]models.Clocking)
+	var diagnostics Diagnostics
+
+	days = slices.Clone(days)
+	slices.SortStableFunc(days, func(a, b models.MWTDag) int {
+		return strings.Compare(a.Dato, b.Dato)
+	})
+
+	for i, day := range days {
+		parser := &dayParser{date: day.Dato, options: options}
+		if i > 0 && days[i-1].Dato == day.Dato {
+			parser.fatal("dagen er med flere ganger i timelisten")
+			diagnostics = append(diagnostics, parser.diagnostics...)
+			continue
+		}
+
+		ts, ok := parser.parseDay(day, overflow)
+		diagnostics = append(diagnostics, parser.diagnostics...)
+		if ok {
+			timesheet[ts.Date.Format(dateFormat)] = ts
+		}
+	}
+
+	return timesheet, append(diagnostics, missingOverflow(overflow)...)
+}
+
+// missingOverflow returnerer en advarsel for hver dag som stemplinger har gått over midnatt til, men som ikke er med
+// i timelisten. Er det vakt den dagen, feiler beregningen fordi dagen mangler, så minuttene blir aldri betalt feil.
+func missingOverflow(overflow map[string][]models.Clocking) Diagnostics {
+	var diagnostics Diagnostics
+	for _, date := range slices.Sorted(maps.Keys(overflow)) {
+		for _, clocking := range overflow[date] {
+			diagnostics = append(diagnostics, Diagnostic{
+				Date:     date,
+				Severity: Warning,
+				Message:  fmt.Sprintf("arbeidstid kl %v-%v fra dagen før er ikke med, fordi dagen mangler i timelisten", clocking.In.Format("15:04"), clocking.Out.Format("15:04")),
+			})
+		}
+	}
+	return diagnostics
+}
+
+func (p *dayParser) parseDay(day models.MWTDag, overflow map[string][]models.Clocking) (models.TimeSheet, bool) {
+	date, err := time.Parse(DateTimeFormat, day.Dato)
+	if err != nil {
+		p.fatal("ugyldig dato %q", day.Dato)
+		return models.TimeSheet{}, false
+	}
+	p.date = date.Format(dateFormat)
+
+	p.options, err = p.options.at(date)
+	if err != nil {
+		p.fatal("fant ingen regler for dagen")
+		return models.TimeSheet{}, false
+	}
+
+	stilling, err := selectStilling(day.Stillinger)
+	if err != nil {
+		p.fatal("%v", err)
+		return models.TimeSheet{}, false
+	}
+
+	ts := models.TimeSheet{
+		Date:          date,
+		WorkingHours:  day.SkjemaTid,
+		WorkingDay:    day.Virkedag,
+		FormName:      day.SkjemaNavn,
+		Salary:        decimal.NewFromInt(int64(stilling.RATEK001)),
+		Stillingskode: stilling.Stillingskode,
+		Clockings:     []models.Clocking{},
+	}
+
+	ts.Clockings = append(ts.Clockings, overflow[p.date]...)
+	delete(overflow, p.date)
+
+	daySkjema := skjema.Parse(day.SkjemaNavn, day.SkjemaTid)
+	if len(day.Stemplinger) == 0 {
+		if day.SkjemaTid != 0 {
+			ts.Clockings = append(ts.Clockings, createPerfectClocking(daySkjema, date))
+		}
+		return ts, true
+	}
+
+	stamps, ok := p.readStamps(day.Stemplinger)
+	if !ok {
+		return models.TimeSheet{}, false
+	}
+
+	clockings, ok := p.pairStamps(stamps, daySkjema, date)
+	if !ok {
+		return models.TimeSheet{}, false
+	}
+
+	for _, clocking := range clockings {
+		for _, part := range splitAtMidnight(clocking) {
+			partDate := part.In.Format(dateFormat)
+			if partDate == p.date {
+				ts.Clockings = append(ts.Clockings, part)
+			} else {
+				overflow[partDate] = append(overflow[partDate], part)
+			}
+		}
+	}
+
+	return ts, true
+}
+
+// readStamps tolker og sorterer stemplingene for en dag
+func (p *dayParser) readStamps(stemplinger []models.MWTStempling) ([]stamp, bool) {
+	stamps := make([]stamp, 0, len(stemplinger))
+	for _, stempling := range stemplinger {
+		stemplingTid, err := time.Parse(DateTimeFormat, stempling.StemplingTid)
+		if err != nil {
+			p.fatal("ugyldig tidspunkt %q på stempling", stempling.StemplingTid)
+			return nil, false
+		}
+
+		s := stamp{
+			MWTStempling: stempling,
+			time:         stemplingTid,
+			direction:    directionOf(stempling),
+		}
+
+		if s.direction == directionUnknown {
+			p.fatal("ukjent stempling %q (%v) kl %v", stempling.Retning, stempling.Type, stemplingTid.Format("15:04"))
+			return nil, false
+		}
+
+		if !slices.Contains(knownTypes, stempling.Type) {
+			p.warn("ukjent stemplingstype %v for %q kl %v, tolket ut fra navnet", stempling.Type, stempling.Retning, stemplingTid.Format("15:04"))
+		}
+
+		if slices.ContainsFunc(stamps, func(other stamp) bool { return other.MWTStempling == stempling }) {
+			p.warn("stempling %q kl %v er registrert flere ganger, og telles bare én gang", stempling.Retning, stemplingTid.Format("15:04"))
+			continue
+		}
+
+		stamps = append(stamps, s)
+	}
+
+	// Stemplinger på samme tidspunkt sorteres slik at man går ut før man kommer inn igjen, og at
+	// overtid kommer etter inn. Da blir resultatet det samme uansett rekkefølgen fra MinWinTid.
+	slices.SortStableFunc(stamps, func(a, b stamp) int {
+		if c := a.time.Compare(b.time); c != 0 {
+			return c
+		}
+		if c := directionOrder(a.direction) - directionOrder(b.direction); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Type, b.Type); c != 0 {
+			return c
+		}
+		return strings.Compare(a.OvertidBegrunnelse, b.OvertidBegrunnelse)
+	})
+
+	return stamps, true
+}
+
+// pairStamps går gjennom stemplingene og lager arbeidsperioder fra hver inn-stempling til neste
+// ut-stempling. Overtidsstemplinger mellom inn og ut gjør perioden til overtid.
+func (p *dayParser) pairStamps(stamps []stamp, daySkjema skjema.Skjema, date time.Time) ([]models.Clocking, bool) {
+	var clockings []models.Clocking
+
+	for i := 0; i < len(stamps); {
+		in := stamps[i]
+		if in.direction != directionIn {
+			p.fatal("forventet inn-stempling kl %v, fikk %q (%v)", in.time.Format("15:04"), in.Retning, in.Type)
+			return nil, false
+		}
+
+		j := i + 1
+		var overtime, overtimeBecauseOfGuardDuty bool
+		var marking string
+		// Man kan ha flere overtidsstemplinger etter hverandre, så vi må sjekke om minst en av dem er BV
+		for j < len(stamps) && stamps[j].direction == directionOvertime {
+			overtime = true
+			if !overtimeBecauseOfGuardDuty {
+				marking, overtimeBecauseOfGuardDuty = p.options.GuardDutyMarking.Match(stamps[j].MWTStempling)
+			}
+			j++
+		}
+
+		if j >= len(stamps) {
+			p.fatal("mangler ut-stempling etter %q kl %v", stamps[j-1].Retning, stamps[j-1].time.Format("15:04"))
+			return nil, false
+		}
+
+		out := stamps[j]
+		if out.direction != directionOut {
+			p.fatal("forventet ut-stempling kl %v, fikk %q (%v)", out.time.Format("15:04"), out.Retning, out.Type)
+			return nil, false
+		}
+
+		i = j + 1
+
+		if !overtime && isFullDayAbsence(in, out) {
+			clockings = append(clockings, createPerfectClocking(daySkjema, date))
+
+			// Heldagsfravær kan avsluttes med inn fra fravær og ut, som da ikke er arbeidstid
+			if i+1 < len(stamps) && stamps[i].Type == "B4" && stamps[i+1].Type == "B2" {
+				i += 2
+			}
+			continue
+		}
+
+		// Tidligere versjoner av særavtalen hadde ikke krav om å merke overtiden sin med BV
+		if overtime && p.options.AllOvertimeIsGuardDuty {
+			overtimeBecauseOfGuardDuty = true
+		}
+
+		clockings = append(clockings, models.Clocking{
+			In:               in.time,
+			Out:              out.time,
+			OtG:              overtime && overtimeBecauseOfGuardDuty,
+			Overtime:         overtime,
+			GuardDutyMarking: marking,
+		})
+	}
+
+	return clockings, true
+}
+
+// isFullDayAbsence sjekker om stemplingene er en heldagsstempling, som MinWinTid registrerer som inn
+// kl 08:00:00 og ut på fravær kl 08:00:01.
+func isFullDayAbsence(in, out stamp) bool {
+	return in.Type == "B1" && out.Type == "B5" &&
+		in.time.Hour() == 8 && in.time.Minute() == 0 && in.time.Second() == 0 &&
+		out.time.Hour() == 8 && out.time.Minute() == 0 && out.time.Second() == 1
+}
+
+// splitAtMidnight deler en arbeidsperiode som går over midnatt i en periode per dag
+func splitAtMidnight(clocking models.Clocking) []models.Clocking {
+	var parts []models.Clocking
+	for {
+		midnight := time.Date(clocking.In.Year(), clocking.In.Month(), clocking.In.Day()+1, 0, 0, 0, 0, time.UTC)
+		if !clocking.Out.After(midnight) {
+			return append(parts, clocking)
+		}
+
+		part := clocking
+		part.Out = midnight
+		parts = append(parts, part)
+		clocking.In = midnight
+	}
+}
+
+// UnmarkedOvertime returnerer en advarsel for hver overtid under beredskapsvakt som ikke er merket med BV, og
+// derfor ikke er regnet som utrykning, slik at vakthaver kan rette opp timelisten.
+func UnmarkedOvertime(timesheet map[string]models.TimeSheet, schedule map[string][]models.Period) Diagnostics {
+	var guardDuty intervals.Set
+	for _, periods := range schedule {
+		for _, period := range periods {
+			guardDuty = guardDuty.Union(intervals.Between(period.Begin, period.End))
+		}
+	}
+
+	var diagnostics Diagnostics
+	for date, day := range timesheet {
+		for _, clocking := range day.Clockings {
+			if !clocking.Overtime || clocking.OtG {
+				continue
+			}
+
+			if guardDuty.Intersect(intervals.Between(clocking.In, clocking.Out)).IsEmpty() {
+				continue
+			}
+
+			diagnostics = append(diagnostics, Diagnostic{
+				Date:     date,
+				Severity: Warning,
+				Message:  fmt.Sprintf("overtid kl %v-%v under beredskapsvakt er ikke merket med BV, og er ikke regnet som utrykning", clocking.In.Format("15:04"), clocking.Out.Format("15:04")),
+			})
+		}
+	}
+
+	slices.SortFunc(diagnostics, func(a, b Diagnostic) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return diagnostics
+}
+
+// createPerfectClocking lager en stempling for en arbeidsdag slik den er definert i skjemaet
+func createPerfectClocking(daySkjema skjema.Skjema, date time.Time) models.Clocking {
+	return models.Clocking{
+		In:  daySkjema.Begin(date),
+		Out: daySkjema.End(date),
+	}
+}
+
+// selectStilling velger hvilken stilling som skal brukes for en dag. Har man flere stillinger samtidig,
+// så er det stillingen med høyest stillingsprosent som gjelder. Like stillinger (samme stillingskode og
+// lønn) regnes som én. Har flere ulike stillinger samme høyeste stillingsprosent vet vi ikke hvilken
+// stilling vakten tilhører, og da feiler vi heller enn å gjette.
+func selectStilling(stillinger []models.MWTStilling) (models.MWTStilling, error) {
+	if len(stillinger) == 0 {
+		return models.MWTStilling{}, fmt.Errorf("ingen stilling registrert")
+	}
+
+	// Like stillinger slås sammen først, slik at en stilling som er registrert flere ganger ikke ser ut som to ulike
+	var unique []models.MWTStilling
+	for _, stilling := range stillinger {
+		i := slices.IndexFunc(unique, func(u models.MWTStilling) bool {
+			return u.Stillingskode == stilling.Stillingskode && u.RATEK001 == stilling.RATEK001
+		})
+		if i < 0 {
+			unique = append(unique, stilling)
+			continue
+		}
+		unique[i].ParttimePct = max(unique[i].ParttimePct, stilling.ParttimePct)
+	}
+
+	selected := unique[0]
+	ambiguous := false
+	for _, stilling := range unique[1:] {
+		if stilling.ParttimePct > selected.ParttimePct {
+			selected = stilling
+			ambiguous = false
+		} else if stilling.ParttimePct == selected.ParttimePct {
+			ambiguous = true
+		}
+	}
+
+	if ambiguous {
+		return models.MWTStilling{}, fmt.Errorf("flere stillinger med %v%% stillingsprosent, klarer ikke velge en av dem", selected.ParttimePct)
+	}
+
+	return selected, nil
+}
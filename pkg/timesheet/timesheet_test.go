@@ -0,0 +1,2091 @@
+package timesheet
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/navikt/vaktor-lonn/pkg/models"
+	"github.com/navikt/vaktor-lonn/pkg/skjema"
+	"github.com/shopspring/decimal"
+)
+
+func TestParse(t *testing.T) {
+	type args struct {
+		days    []models.MWTDag
+		options Options
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]models.TimeSheet
+		wantErr bool
+	}{
+		{
+			name: "arbeidsdag med litt fravær",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-08-02T00:00:00",
+						SkjemaTid:  7,
+						SkjemaNavn: "Heltid 0800-1500 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-08-02T07:45:10",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-08-02T14:30:11",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-08-02T14:31:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-08-02T14:31:01",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   940,
+							},
+							{
+								StemplingTid: "2022-08-02T16:00:00",
+								Retning:      "Inn fra fravær",
+								Type:         "B4",
+								Fravarkode:   940,
+							},
+							{
+								StemplingTid: "2022-08-02T16:00:01",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-08-02": {
+					Date:         time.Date(2022, 8, 2, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7,
+					WorkingDay:   "Virkedag",
+					FormName:     "Heltid 0800-1500 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 8, 2, 7, 45, 10, 0, time.UTC),
+							Out: time.Date(2022, 8, 2, 14, 30, 11, 0, time.UTC),
+						},
+						{
+							In:  time.Date(2022, 8, 2, 14, 31, 0, 0, time.UTC),
+							Out: time.Date(2022, 8, 2, 14, 31, 1, 0, time.UTC),
+						},
+						{
+							In:  time.Date(2022, 8, 2, 16, 0, 0, 0, time.UTC),
+							Out: time.Date(2022, 8, 2, 16, 0, 1, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "arbeidsdag med to fravær",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2023-06-07T00:00:00",
+						SkjemaTid:  7,
+						SkjemaNavn: "Heltid 0800-1500 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2023-06-07T08:29:46",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2023-06-07T11:43:10",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   180,
+							},
+							{
+								StemplingTid: "2023-06-07T12:39:09",
+								Retning:      "Inn fra fravær",
+								Type:         "B4",
+								Fravarkode:   180,
+							},
+							{
+								StemplingTid: "2023-06-07T12:40:00",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   920,
+							},
+							{
+								StemplingTid: "2023-06-07T13:30:00",
+								Retning:      "Inn fra fravær",
+								Type:         "B4",
+								Fravarkode:   920,
+							},
+							{
+								StemplingTid: "2023-06-07T15:11:43",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2023-06-07": {
+					Date:         time.Date(2023, 6, 7, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7,
+					WorkingDay:   "Virkedag",
+					FormName:     "Heltid 0800-1500 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2023, 6, 7, 8, 29, 46, 0, time.UTC),
+							Out: time.Date(2023, 6, 7, 11, 43, 10, 0, time.UTC),
+						},
+						{
+							In:  time.Date(2023, 6, 7, 12, 39, 9, 0, time.UTC),
+							Out: time.Date(2023, 6, 7, 12, 40, 0, 0, time.UTC),
+						},
+						{
+							In:  time.Date(2023, 6, 7, 13, 30, 0, 0, time.UTC),
+							Out: time.Date(2023, 6, 7, 15, 11, 43, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "kveld med utrykning (glemt BV begrunnelse)",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2023-02-14T00:00:00",
+						SkjemaTid:  7.45,
+						SkjemaNavn: "Heltid 0800-1545 (2018)",
+						Godkjent:   5,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2023-02-14T08:00:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2023-02-14T15:45:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2023-02-14T20:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2023-02-14T22:29:59",
+								Retning:      "Overtid",
+								Type:         "B6",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2023-02-14T22:30:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2023-02-14": {
+					Date:         time.Date(2023, 2, 14, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.45,
+					WorkingDay:   "Virkedag",
+					FormName:     "Heltid 0800-1545 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2023, 2, 14, 8, 0, 0, 0, time.UTC),
+							Out: time.Date(2023, 2, 14, 15, 45, 0, 0, time.UTC),
+						},
+						{
+							In:       time.Date(2023, 2, 14, 20, 30, 0, 0, time.UTC),
+							Out:      time.Date(2023, 2, 14, 22, 30, 0, 0, time.UTC),
+							Overtime: true,
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "helg med utrykning (liten og stor BV begrunnelse)",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2023-02-04T00:00:00",
+						SkjemaTid:  0,
+						SkjemaNavn: "BV Lørdag IKT",
+						Godkjent:   5,
+						Virkedag:   "Lørdag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2023-02-04T20:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2023-02-04T22:29:59",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV",
+							},
+							{
+								StemplingTid: "2023-02-04T22:30:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+					{
+						Dato:       "2023-02-11T00:00:00",
+						SkjemaTid:  0,
+						SkjemaNavn: "BV Lørdag IKT",
+						Godkjent:   5,
+						Virkedag:   "Lørdag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2023-02-11T20:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2023-02-11T22:29:59",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "bv",
+							},
+							{
+								StemplingTid: "2023-02-11T22:30:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2023-02-04": {
+					Date:         time.Date(2023, 2, 4, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 0,
+					WorkingDay:   "Lørdag",
+					FormName:     "BV Lørdag IKT",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2023, 2, 4, 20, 30, 0, 0, time.UTC),
+							Out:              time.Date(2023, 2, 4, 22, 30, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+					},
+				},
+				"2023-02-11": {
+					Date:         time.Date(2023, 2, 11, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 0,
+					WorkingDay:   "Lørdag",
+					FormName:     "BV Lørdag IKT",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2023, 2, 11, 20, 30, 0, 0, time.UTC),
+							Out:              time.Date(2023, 2, 11, 22, 30, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "bv",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "helg med utrykning (før krav om BV begrunnelse)",
+			args: args{
+				options: Options{AllOvertimeIsGuardDuty: true},
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-09-17T00:00:00",
+						SkjemaTid:  0,
+						SkjemaNavn: "BV Lørdag IKT",
+						Godkjent:   5,
+						Virkedag:   "Lørdag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-09-17T20:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-17T22:29:59",
+								Retning:      "Overtid",
+								Type:         "B6",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-17T22:30:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-09-24T00:00:00",
+						SkjemaTid:  0,
+						SkjemaNavn: "BV Lørdag IKT",
+						Godkjent:   5,
+						Virkedag:   "Lørdag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-09-24T20:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-24T22:29:59",
+								Retning:      "Overtid",
+								Type:         "B6",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-24T22:30:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-09-17": {
+					Date:         time.Date(2022, 9, 17, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 0,
+					WorkingDay:   "Lørdag",
+					FormName:     "BV Lørdag IKT",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:       time.Date(2022, 9, 17, 20, 30, 0, 0, time.UTC),
+							Out:      time.Date(2022, 9, 17, 22, 30, 0, 0, time.UTC),
+							OtG:      true,
+							Overtime: true,
+						},
+					},
+				},
+				"2022-09-24": {
+					Date:         time.Date(2022, 9, 24, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 0,
+					WorkingDay:   "Lørdag",
+					FormName:     "BV Lørdag IKT",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:       time.Date(2022, 9, 24, 20, 30, 0, 0, time.UTC),
+							Out:      time.Date(2022, 9, 24, 22, 30, 0, 0, time.UTC),
+							OtG:      true,
+							Overtime: true,
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Heldags Kurs/Seminar",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-05-03T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "Heltid 0800-1545 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-05-03T08:00:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-05-03T08:00:01",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   740,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-05-03": {
+					Date:         time.Date(2022, 5, 3, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "Heltid 0800-1545 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 5, 3, 8, 0, 0, 0, time.UTC),
+							Out: time.Date(2022, 5, 3, 15, 45, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Heldags fravær",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-10-17T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "Heltid 0800-1545 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-10-17T08:00:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-10-17T08:00:01",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   630,
+							},
+							{
+								StemplingTid: "2022-10-17T15:45:00",
+								Retning:      "Inn fra fravær",
+								Type:         "B4",
+								Fravarkode:   630,
+							},
+							{
+								StemplingTid: "2022-10-17T15:45:01",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-10-17": {
+					Date:         time.Date(2022, 10, 17, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "Heltid 0800-1545 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 17, 8, 0, 0, 0, time.UTC),
+							Out: time.Date(2022, 10, 17, 15, 45, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Inn fra fravær",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-10-20T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "Heltid 0800-1545 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-10-20T11:12:10",
+								Retning:      "Inn fra fravær",
+								Type:         "B4",
+								Fravarkode:   630,
+							},
+							{
+								StemplingTid: "2022-10-20T16:00:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-10-20": {
+					Date:         time.Date(2022, 10, 20, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "Heltid 0800-1545 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 20, 11, 12, 10, 0, time.UTC),
+							Out: time.Date(2022, 10, 20, 16, 0, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "To overtid på natten",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-09-15T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-09-15T00:34:21",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-09-15T00:34:24",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV - IKT-478705 DVH",
+							},
+							{
+								StemplingTid:       "2022-09-15T01:34:42",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid: "2022-09-15T03:10:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2022-09-15T03:31:00",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV",
+							},
+							{
+								StemplingTid: "2022-09-15T04:32:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-15T08:04:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-15T16:26:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-09-15": {
+					Date:         time.Date(2022, 9, 15, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2022, 9, 15, 0, 34, 21, 0, time.UTC),
+							Out:              time.Date(2022, 9, 15, 1, 34, 42, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:               time.Date(2022, 9, 15, 3, 10, 0, 0, time.UTC),
+							Out:              time.Date(2022, 9, 15, 4, 32, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:  time.Date(2022, 9, 15, 8, 4, 0, 0, time.UTC),
+							Out: time.Date(2022, 9, 15, 16, 26, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Overtid over midnatt, ikke vakt dagen etterpå",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-09-15T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-09-15T08:04:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-15T16:26:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-15T23:10:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2022-09-15T23:31:00",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV",
+							},
+							{
+								StemplingTid: "2022-09-16T00:32:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-09-15": {
+					Date:         time.Date(2022, 9, 15, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 9, 15, 8, 4, 0, 0, time.UTC),
+							Out: time.Date(2022, 9, 15, 16, 26, 0, 0, time.UTC),
+						},
+						{
+							In:               time.Date(2022, 9, 15, 23, 10, 0, 0, time.UTC),
+							Out:              time.Date(2022, 9, 16, 0, 0, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Overtid over midnatt, med vakt påfølgende dag",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-09-15T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-09-15T08:04:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-15T16:26:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-15T23:10:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2022-09-15T23:31:00",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV",
+							},
+							{
+								StemplingTid: "2022-09-16T00:32:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-09-16T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-09-16T08:04:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-09-16T15:41:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-09-15": {
+					Date:         time.Date(2022, 9, 15, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 9, 15, 8, 4, 0, 0, time.UTC),
+							Out: time.Date(2022, 9, 15, 16, 26, 0, 0, time.UTC),
+						},
+						{
+							In:               time.Date(2022, 9, 15, 23, 10, 0, 0, time.UTC),
+							Out:              time.Date(2022, 9, 16, 0, 0, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+					},
+				},
+				"2022-09-16": {
+					Date:         time.Date(2022, 9, 16, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2022, 9, 16, 0, 0, 0, 0, time.UTC),
+							Out:              time.Date(2022, 9, 16, 0, 32, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:  time.Date(2022, 9, 16, 8, 4, 0, 0, time.UTC),
+							Out: time.Date(2022, 9, 16, 15, 41, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Utrykning på natten, og påfølgende kveld over midnatt, med jobb påfølgende dag",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-10-25T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-25T00:34:21",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-25T00:34:24",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV - IKT-478705 DVH",
+							},
+							{
+								StemplingTid:       "2022-10-25T01:34:42",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-25T06:34:45",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-25T07:18:43",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-25T08:47:49",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-25T15:48:30",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-25T23:31:37",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-26T00:45:34",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         1,
+								OvertidBegrunnelse: "BV - Feilsøking ifbm høy load på CICSP460, IKT-479284 DVH, IKT-479282 KUHR",
+							},
+							{
+								StemplingTid:       "2022-10-26T00:45:35",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-26T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-26T08:00:00",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-26T15:45:00",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-10-25": {
+					Date:         time.Date(2022, 10, 25, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2022, 10, 25, 0, 34, 21, 0, time.UTC),
+							Out:              time.Date(2022, 10, 25, 1, 34, 42, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:  time.Date(2022, 10, 25, 6, 34, 45, 0, time.UTC),
+							Out: time.Date(2022, 10, 25, 7, 18, 43, 0, time.UTC),
+						},
+						{
+							In:  time.Date(2022, 10, 25, 8, 47, 49, 0, time.UTC),
+							Out: time.Date(2022, 10, 25, 15, 48, 30, 0, time.UTC),
+						},
+						{
+							In:               time.Date(2022, 10, 25, 23, 31, 37, 0, time.UTC),
+							Out:              time.Date(2022, 10, 26, 0, 0, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+					},
+				},
+				"2022-10-26": {
+					Date:         time.Date(2022, 10, 26, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2022, 10, 26, 0, 0, 0, 0, time.UTC),
+							Out:              time.Date(2022, 10, 26, 0, 45, 35, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:  time.Date(2022, 10, 26, 8, 0, 0, 0, time.UTC),
+							Out: time.Date(2022, 10, 26, 15, 45, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Lang dag, overtid på kvelden, og overtid over midnatt, med kort vakt påfølgende dag",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-10-18T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-10-18T08:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-10-18T17:00:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-10-18T20:00:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2022-10-18T20:59:59",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "BV",
+							},
+							{
+								StemplingTid: "2022-10-18T21:00:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-10-18T23:30:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid:       "2022-10-19T00:29:59",
+								Retning:            "Overtid",
+								Type:               "B6",
+								Fravarkode:         1,
+								OvertidBegrunnelse: "BV",
+							},
+							{
+								StemplingTid: "2022-10-19T00:30:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-19T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-10-19T08:00:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-10-19T17:00:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-10-18": {
+					Date:         time.Date(2022, 10, 18, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 18, 8, 30, 0, 0, time.UTC),
+							Out: time.Date(2022, 10, 18, 17, 0, 0, 0, time.UTC),
+						},
+						{
+							In:               time.Date(2022, 10, 18, 20, 0, 0, 0, time.UTC),
+							Out:              time.Date(2022, 10, 18, 21, 0, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:               time.Date(2022, 10, 18, 23, 30, 0, 0, time.UTC),
+							Out:              time.Date(2022, 10, 19, 0, 0, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+					},
+				},
+				"2022-10-19": {
+					Date:         time.Date(2022, 10, 19, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:               time.Date(2022, 10, 19, 0, 0, 0, 0, time.UTC),
+							Out:              time.Date(2022, 10, 19, 0, 30, 0, 0, time.UTC),
+							OtG:              true,
+							Overtime:         true,
+							GuardDutyMarking: "BV",
+						},
+						{
+							In:  time.Date(2022, 10, 19, 8, 0, 0, 0, time.UTC),
+							Out: time.Date(2022, 10, 19, 17, 0, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Kun ut på fravær",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-01-20T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-01-20T08:09:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-01-20T14:34:00",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   470,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-01-20": {
+					Date:         time.Date(2022, 1, 20, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 1, 20, 8, 9, 0, 0, time.UTC),
+							Out: time.Date(2022, 1, 20, 14, 34, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Ut på frævar, så inn igjen",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-01-24T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   3,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid: "2022-01-24T08:27:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-01-24T10:01:00",
+								Retning:      "Ut på fravær",
+								Type:         "B5",
+								Fravarkode:   180,
+							},
+							{
+								StemplingTid: "2022-01-24T11:27:00",
+								Retning:      "Inn",
+								Type:         "B1",
+								Fravarkode:   0,
+							},
+							{
+								StemplingTid: "2022-01-24T15:45:00",
+								Retning:      "Ut",
+								Type:         "B2",
+								Fravarkode:   0,
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 500_000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-01-24": {
+					Date:         time.Date(2022, 1, 24, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "NY BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(500_000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 1, 24, 8, 27, 0, 0, time.UTC),
+							Out: time.Date(2022, 1, 24, 10, 1, 0, 0, time.UTC),
+						},
+						{
+							In:  time.Date(2022, 1, 24, 11, 27, 0, 0, time.UTC),
+							Out: time.Date(2022, 1, 24, 15, 45, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "En tilfeldig døgnkontinuerlig vaktuke",
+			args: args{
+				days: []models.MWTDag{
+					{
+						Dato:       "2022-10-05T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   2,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-05T07:21:42",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-05T15:24:14",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-06T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   2,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-06T07:13:24",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-06T15:03:51",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-07T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   2,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-07T07:18:52",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-07T15:06:59",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:        "2022-10-08T00:00:00",
+						SkjemaTid:   0,
+						SkjemaNavn:  "BV Lørdag IKT",
+						Godkjent:    2,
+						Virkedag:    "Lørdag",
+						Stemplinger: nil,
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:        "2022-10-09T00:00:00",
+						SkjemaTid:   0,
+						SkjemaNavn:  "BV Søndag IKT",
+						Godkjent:    2,
+						Virkedag:    "Søndag",
+						Stemplinger: nil,
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-10T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   2,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-10T07:18:32",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-10T15:25:00",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-11T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   2,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-11T07:09:58",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-11T15:23:41",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+					{
+						Dato:       "2022-10-12T00:00:00",
+						SkjemaTid:  7.75,
+						SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+						Godkjent:   2,
+						Virkedag:   "Virkedag",
+						Stemplinger: []models.MWTStempling{
+							{
+								StemplingTid:       "2022-10-12T08:00:00",
+								Retning:            "Inn",
+								Type:               "B1",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+							{
+								StemplingTid:       "2022-10-12T09:00:00",
+								Retning:            "Ut",
+								Type:               "B2",
+								Fravarkode:         0,
+								OvertidBegrunnelse: "",
+							},
+						},
+						Stillinger: []models.MWTStilling{
+							{
+								RATEK001: 725000,
+							},
+						},
+					},
+				},
+			},
+			want: map[string]models.TimeSheet{
+				"2022-10-05": {
+					Date:         time.Date(2022, 10, 5, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(725000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 5, 7, 21, 42, 0, time.UTC),
+							Out: time.Date(2022, 10, 5, 15, 24, 14, 0, time.UTC),
+						},
+					},
+				},
+				"2022-10-06": {
+					Date:         time.Date(2022, 10, 6, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(725000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 6, 7, 13, 24, 0, time.UTC),
+							Out: time.Date(2022, 10, 6, 15, 3, 51, 0, time.UTC),
+						},
+					},
+				},
+				"2022-10-07": {
+					Date:         time.Date(2022, 10, 7, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(725000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 7, 7, 18, 52, 0, time.UTC),
+							Out: time.Date(2022, 10, 7, 15, 6, 59, 0, time.UTC),
+						},
+					},
+				},
+				"2022-10-08": {
+					Date:       time.Date(2022, 10, 8, 0, 0, 0, 0, time.UTC),
+					WorkingDay: "Lørdag",
+					FormName:   "BV Lørdag IKT",
+					Salary:     decimal.NewFromInt(725000),
+					Clockings:  []models.Clocking{},
+				},
+				"2022-10-09": {
+					Date:       time.Date(2022, 10, 9, 0, 0, 0, 0, time.UTC),
+					WorkingDay: "Søndag",
+					FormName:   "BV Søndag IKT",
+					Salary:     decimal.NewFromInt(725000),
+					Clockings:  []models.Clocking{},
+				},
+				"2022-10-10": {
+					Date:         time.Date(2022, 10, 10, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(725000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 10, 7, 18, 32, 0, time.UTC),
+							Out: time.Date(2022, 10, 10, 15, 25, 0, 0, time.UTC),
+						},
+					},
+				},
+				"2022-10-11": {
+					Date:         time.Date(2022, 10, 11, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(725000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 11, 7, 9, 58, 0, time.UTC),
+							Out: time.Date(2022, 10, 11, 15, 23, 41, 0, time.UTC),
+						},
+					},
+				},
+				"2022-10-12": {
+					Date:         time.Date(2022, 10, 12, 0, 0, 0, 0, time.UTC),
+					WorkingHours: 7.75,
+					WorkingDay:   "Virkedag",
+					FormName:     "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Salary:       decimal.NewFromInt(725000),
+					Clockings: []models.Clocking{
+						{
+							In:  time.Date(2022, 10, 12, 8, 0, 0, 0, time.UTC),
+							Out: time.Date(2022, 10, 12, 9, 0, 0, 0, time.UTC),
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diagnostics := Parse(tt.args.days, tt.args.options)
+			if diagnostics.HasFatal() != tt.wantErr {
+				t.Errorf("Parse() diagnostics = %v, wantErr %v", diagnostics, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Parse() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_createPerfectClocking(t *testing.T) {
+	type args struct {
+		tid      float64
+		formName string
+		date     time.Time
+	}
+	tests := []struct {
+		name string
+		args args
+		want models.Clocking
+	}{
+		{
+			name: "Vintertid",
+			args: args{
+				tid:  7.75,
+				date: time.Date(2022, 11, 6, 0, 0, 0, 0, time.UTC),
+			},
+			want: models.Clocking{
+				In:  time.Date(2022, 11, 6, 8, 0, 0, 0, time.UTC),
+				Out: time.Date(2022, 11, 6, 15, 45, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Sommertid",
+			args: args{
+				tid:  7,
+				date: time.Date(2022, 11, 6, 0, 0, 0, 0, time.UTC),
+			},
+			want: models.Clocking{
+				In:  time.Date(2022, 11, 6, 8, 0, 0, 0, time.UTC),
+				Out: time.Date(2022, 11, 6, 15, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Normaltid",
+			args: args{
+				tid:  7.5,
+				date: time.Date(2022, 11, 6, 0, 0, 0, 0, time.UTC),
+			},
+			want: models.Clocking{
+				In:  time.Date(2022, 11, 6, 8, 0, 0, 0, time.UTC),
+				Out: time.Date(2022, 11, 6, 15, 30, 0, 0, time.UTC),
+			},
+		},
+		{
+			name: "Deltid som starter senere på dagen",
+			args: args{
+				tid:      4,
+				formName: "Deltid 1200-1600",
+				date:     time.Date(2022, 11, 7, 0, 0, 0, 0, time.UTC),
+			},
+			want: models.Clocking{
+				In:  time.Date(2022, 11, 7, 12, 0, 0, 0, time.UTC),
+				Out: time.Date(2022, 11, 7, 16, 0, 0, 0, time.UTC),
+			},
+		}, {
+			name: "Nyttårsaften starter arbeidsdagen som vanlig",
+			args: args{
+				tid:      2,
+				formName: "Nyttårsaften 1000-1200 *",
+				date:     time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
+			want: models.Clocking{
+				In:  time.Date(2022, 12, 31, 8, 0, 0, 0, time.UTC),
+				Out: time.Date(2022, 12, 31, 10, 0, 0, 0, time.UTC),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := createPerfectClocking(skjema.Parse(tt.args.formName, tt.args.tid), tt.args.date)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("createPerfectClocking() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func Test_selectStilling(t *testing.T) {
+	tests := []struct {
+		name       string
+		stillinger []models.MWTStilling
+		want       models.MWTStilling
+		wantErr    bool
+	}{
+		{
+			name:    "Ingen stillinger",
+			wantErr: true,
+		},
+		{
+			name: "Én stilling",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 500_000, Stillingskode: "258", ParttimePct: 100},
+			},
+			want: models.MWTStilling{RATEK001: 500_000, Stillingskode: "258", ParttimePct: 100},
+		},
+		{
+			name: "Hovedstilling og bistilling",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 400_000, Stillingskode: "1065", ParttimePct: 20},
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 80},
+			},
+			want: models.MWTStilling{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 80},
+		},
+		{
+			name: "Samme stilling registrert to ganger",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 50},
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 50},
+			},
+			want: models.MWTStilling{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 50},
+		},
+		{
+			name: "To ulike stillinger med lik stillingsprosent",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 50},
+				{RATEK001: 550_000, Stillingskode: "265", ParttimePct: 50},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Lik stillingsprosent, men en annen stilling er større",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 30},
+				{RATEK001: 550_000, Stillingskode: "265", ParttimePct: 30},
+				{RATEK001: 700_000, Stillingskode: "1065", ParttimePct: 40},
+			},
+			want: models.MWTStilling{RATEK001: 700_000, Stillingskode: "1065", ParttimePct: 40},
+		},
+		{
+			name: "Samme stilling registrert to ganger med en annen stilling i mellom",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 50},
+				{RATEK001: 550_000, Stillingskode: "265", ParttimePct: 50},
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 90},
+			},
+			want: models.MWTStilling{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 90},
+		},
+		{
+			name: "Stillingen som er registrert to ganger er ikke den største",
+			stillinger: []models.MWTStilling{
+				{RATEK001: 550_000, Stillingskode: "265", ParttimePct: 60},
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 40},
+				{RATEK001: 600_000, Stillingskode: "258", ParttimePct: 60},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectStilling(tt.stillinger)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("selectStilling() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("selectStilling() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestParseDiagnostics(t *testing.T) {
+	stillinger := []models.MWTStilling{
+		{
+			RATEK001:      500_000,
+			Stillingskode: "258",
+		},
+	}
+	tests := []struct {
+		name        string
+		stemplinger []models.MWTStempling
+		want        []Severity
+		clockings   int
+	}{
+		{
+			name: "Overtid uten ut-stempling",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Inn", Type: "B1"},
+				{StemplingTid: "2023-03-01T20:00:00", Retning: "Overtid", Type: "B6", OvertidBegrunnelse: "BV"},
+			},
+			want: []Severity{Fatal},
+		},
+		{
+			name: "Kun én stempling",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Inn", Type: "B1"},
+			},
+			want: []Severity{Fatal},
+		},
+		{
+			name: "Starter med ut-stempling",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Ut", Type: "B2"},
+				{StemplingTid: "2023-03-01T15:00:00", Retning: "Inn", Type: "B1"},
+			},
+			want: []Severity{Fatal},
+		},
+		{
+			name: "Ukjent retning",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Pause", Type: "B9"},
+				{StemplingTid: "2023-03-01T15:00:00", Retning: "Ut", Type: "B2"},
+			},
+			want: []Severity{Fatal},
+		},
+		{
+			name: "Retning som starter som en kjent retning",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Inn", Type: "B1"},
+				{StemplingTid: "2023-03-01T15:00:00", Retning: "Utrykning", Type: "B9"},
+			},
+			want: []Severity{Fatal},
+		},
+		{
+			name: "Ugyldig tidspunkt",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "i morgen", Retning: "Inn", Type: "B1"},
+				{StemplingTid: "2023-03-01T15:00:00", Retning: "Ut", Type: "B2"},
+			},
+			want: []Severity{Fatal},
+		},
+		{
+			name: "Ukjent type tolkes ut fra navnet",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Inn", Type: "B7"},
+				{StemplingTid: "2023-03-01T15:00:00", Retning: "Ut", Type: "B2"},
+			},
+			want:      []Severity{Warning},
+			clockings: 1,
+		},
+		{
+			name: "Dobbel stempling telles én gang",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Inn", Type: "B1"},
+				{StemplingTid: "2023-03-01T08:00:00", Retning: "Inn", Type: "B1"},
+				{StemplingTid: "2023-03-01T15:00:00", Retning: "Ut", Type: "B2"},
+			},
+			want:      []Severity{Warning},
+			clockings: 1,
+		},
+		{
+			name: "Overtid etter inn fra fravær",
+			stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T12:00:00", Retning: "Inn fra fravær", Type: "B4"},
+				{StemplingTid: "2023-03-01T17:00:00", Retning: "Overtid", Type: "B6", OvertidBegrunnelse: "BV"},
+				{StemplingTid: "2023-03-01T18:00:00", Retning: "Ut", Type: "B2"},
+			},
+			clockings: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diagnostics := Parse([]models.MWTDag{
+				{
+					Dato:        "2023-03-01T00:00:00",
+					SkjemaTid:   7.75,
+					SkjemaNavn:  "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+					Virkedag:    "Virkedag",
+					Stemplinger: tt.stemplinger,
+					Stillinger:  stillinger,
+				},
+			}, Options{})
+
+			var severities []Severity
+			for _, diagnostic := range diagnostics {
+				severities = append(severities, diagnostic.Severity)
+				if diagnostic.Date != "2023-03-01" {
+					t.Errorf("Parse() diagnostic has date %v, want 2023-03-01", diagnostic.Date)
+				}
+			}
+
+			if diff := cmp.Diff(tt.want, severities); diff != "" {
+				t.Errorf("Parse() diagnostics %v mismatch (-want +got):\n%s", diagnostics, diff)
+			}
+
+			if len(got["2023-03-01"].Clockings) != tt.clockings {
+				t.Errorf("Parse() got %v clockings, want %v", len(got["2023-03-01"].Clockings), tt.clockings)
+			}
+		})
+	}
+}
+
+func TestParseOverflowToMissingDay(t *testing.T) {
+	days := []models.MWTDag{
+		{
+			Dato:       "2023-03-01T00:00:00",
+			SkjemaTid:  7.75,
+			SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+			Virkedag:   "Virkedag",
+			Stemplinger: []models.MWTStempling{
+				{StemplingTid: "2023-03-01T22:00:00", Retning: "Inn", Type: "B1"},
+				{StemplingTid: "2023-03-01T22:05:00", Retning: "Overtid", Type: "B6", OvertidBegrunnelse: "BV"},
+				{StemplingTid: "2023-03-02T01:30:00", Retning: "Ut", Type: "B2"},
+			},
+			Stillinger: []models.MWTStilling{{RATEK001: 500_000, Stillingskode: "258"}},
+		},
+	}
+
+	_, diagnostics := Parse(days, Options{})
+	want := Diagnostics{
+		{
+			Date:     "2023-03-02",
+			Severity: Warning,
+			Message:  "arbeidstid kl 00:00-01:30 fra dagen før er ikke med, fordi dagen mangler i timelisten",
+		},
+	}
+	if diff := cmp.Diff(want, diagnostics); diff != "" {
+		t.Errorf("Parse() diagnostics mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestUnmarkedOvertime(t *testing.T) {
+	timesheet := map[string]models.TimeSheet{
+		"2023-03-01": {
+			Date: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+			Clockings: []models.Clocking{
+				{In: time.Date(2023, 3, 1, 8, 0, 0, 0, time.UTC), Out: time.Date(2023, 3, 1, 15, 45, 0, 0, time.UTC)},
+				{In: time.Date(2023, 3, 1, 12, 0, 0, 0, time.UTC), Out: time.Date(2023, 3, 1, 13, 0, 0, 0, time.UTC), Overtime: true},
+				{In: time.Date(2023, 3, 1, 18, 0, 0, 0, time.UTC), Out: time.Date(2023, 3, 1, 19, 0, 0, 0, time.UTC), Overtime: true, OtG: true, GuardDutyMarking: "BV"},
+				{In: time.Date(2023, 3, 1, 21, 0, 0, 0, time.UTC), Out: time.Date(2023, 3, 1, 22, 30, 0, 0, time.UTC), Overtime: true},
+			},
+		},
+	}
+	schedule := map[string][]models.Period{
+		"2023-03-01": {{Begin: time.Date(2023, 3, 1, 16, 0, 0, 0, time.UTC), End: time.Date(2023, 3, 2, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	want := Diagnostics{
+		{
+			Date:     "2023-03-01",
+			Severity: Warning,
+			Message:  "overtid kl 21:00-22:30 under beredskapsvakt er ikke merket med BV, og er ikke regnet som utrykning",
+		},
+	}
+	if diff := cmp.Diff(want, UnmarkedOvertime(timesheet, schedule)); diff != "" {
+		t.Errorf("UnmarkedOvertime() mismatch (-want +got):\n%s", diff)
+	}
+}
+
+// stamplingerFromBytes lager en tilfeldig rekke med stemplinger for 1. mars 2023, tre bytes per stempling
+func stamplingerFromBytes(data []byte) []models.MWTStempling {
+	retninger := []string{"Inn", "Ut", "Overtid", "Inn fra fravær", "Ut på fravær", "Pause"}
+	begrunnelser := []string{"", "BV", "Oppgradering", "bv - utrykning"}
+	date := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	var stemplinger []models.MWTStempling
+	for i := 0; i+2 < len(data); i += 3 {
+		// Hver byte er seks minutter, så stemplingene kan gå over midnatt
+		tid := date.Add(time.Duration(data[i+1]) * 6 * time.Minute).Add(time.Duration(data[i+2]%60) * time.Second)
+		stemplinger = append(stemplinger, models.MWTStempling{
+			StemplingTid:       tid.Format(DateTimeFormat),
+			Retning:            retninger[int(data[i])%len(retninger)],
+			Type:               fmt.Sprintf("B%d", int(data[i]/8)%8),
+			OvertidBegrunnelse: begrunnelser[int(data[i+2])%len(begrunnelser)],
+		})
+	}
+
+	return stemplinger
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add([]byte{0, 80, 0, 1, 150, 0})
+	f.Add([]byte{0, 80, 0, 2, 200, 1, 1, 245, 0})
+	f.Add([]byte{0, 80, 0, 4, 80, 1, 3, 130, 0, 1, 150, 1})
+	f.Add([]byte{0, 80, 0})
+	f.Add([]byte{2, 80, 0, 0, 20, 5})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		stemplinger := stamplingerFromBytes(data)
+		days := []models.MWTDag{
+			{
+				Dato:        "2023-03-01T00:00:00",
+				SkjemaTid:   7.75,
+				SkjemaNavn:  "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+				Stemplinger: stemplinger,
+				Stillinger:  []models.MWTStilling{{RATEK001: 500_000}},
+			},
+			{
+				Dato:       "2023-03-02T00:00:00",
+				SkjemaTid:  7.75,
+				SkjemaNavn: "BV 0800-1545 m/Beredskapsvakt, start vakt kl 1600 (2018)",
+				Stillinger: []models.MWTStilling{{RATEK001: 500_000}},
+			},
+		}
+
+		got, diagnostics := Parse(days, Options{})
+		for date, ts := range got {
+			begin, _ := time.Parse(dateFormat, date)
+			for _, clocking := range ts.Clockings {
+				if clocking.Out.Before(clocking.In) {
+					t.Errorf("clocking %v ends before it begins", clocking)
+				}
+				if clocking.In.Before(begin) || clocking.Out.After(begin.Add(24*time.Hour)) {
+					t.Errorf("clocking %v is outside of %v", clocking, date)
+				}
+			}
+		}
+
+		// Rekkefølgen fra MinWinTid skal ikke ha noe å si
+		reversed := slices.Clone(stemplinger)
+		slices.Reverse(reversed)
+		days[0].Stemplinger = reversed
+		days[0], days[1] = days[1], days[0]
+
+		gotReversed, diagnosticsReversed := Parse(days, Options{})
+		if diff := cmp.Diff(got, gotReversed); diff != "" {
+			t.Errorf("Parse() depends on the order of the clockings (-original +reversed):\n%s", diff)
+		}
+		if diagnostics.HasFatal() != diagnosticsReversed.HasFatal() {
+			t.Errorf("Parse() depends on the order of the clockings: %v != %v", diagnostics, diagnosticsReversed)
+		}
+	})
+}